@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/floj/aoc2024/lib/geom"
+)
+
+const (
+	highlightNone = ""
+	highlightANSI = "ansi"
+	highlightCase = "case"
+
+	ansiHighlight = "\x1b[1;31m"
+	ansiReset     = "\x1b[0m"
+)
+
+// hit is a match of a word or template reduced to what's needed to report it
+type hit struct {
+	desc  string
+	cells []geom.Vec
+}
+
+func wordHits(m matrix, matches []Match) []hit {
+	hits := make([]hit, 0, len(matches))
+	for _, ma := range matches {
+		hits = append(hits, hit{
+			desc:  fmt.Sprintf("%s at %s heading %s", ma.Word, ma.Pos, ma.Dir),
+			cells: ma.Cells(m),
+		})
+	}
+	return hits
+}
+
+func templateHits(matches []templateMatch) []hit {
+	hits := make([]hit, 0, len(matches))
+	for _, tm := range matches {
+		hits = append(hits, hit{
+			desc:  fmt.Sprintf("template %d at %s %s", tm.template+1, tm.pos, tm.variant.Orientation()),
+			cells: tm.Cells(),
+		})
+	}
+	return hits
+}
+
+// Highlight returns a copy of the matrix where all cells are marked according to style.
+// With highlightCase matched cells are upper case and all others lower case,
+// with highlightANSI matched cells are wrapped in color escape sequences.
+func (m matrix) Highlight(cells map[geom.Vec]bool, style string) rect {
+	r := make(rect, 0, len(m))
+	for y, row := range m {
+		hr := make([]byte, 0, len(row))
+		for x, b := range row {
+			matched := cells[geom.Vec{X: x, Y: y}]
+			switch {
+			case style == highlightANSI && matched:
+				hr = append(hr, ansiHighlight...)
+				hr = append(hr, b)
+				hr = append(hr, ansiReset...)
+			case style == highlightCase && matched:
+				hr = append(hr, toUpper(b))
+			case style == highlightCase:
+				hr = append(hr, toLower(b))
+			default:
+				hr = append(hr, b)
+			}
+		}
+		r = append(r, hr)
+	}
+	return r
+}
+
+func toUpper(b byte) byte {
+	if b >= 'a' && b <= 'z' {
+		return b - 'a' + 'A'
+	}
+	return b
+}
+
+func toLower(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b - 'A' + 'a'
+	}
+	return b
+}
+
+// showHits renders the highlighted matrix and/or the list of matches, depending on opts.
+func showHits(w io.Writer, m matrix, hits []hit, opts options) {
+	if opts.highlight != highlightNone {
+		cells := map[geom.Vec]bool{}
+		for _, h := range hits {
+			for _, c := range h.cells {
+				cells[c] = true
+			}
+		}
+		fmt.Fprintf(w, "%s\n", m.Highlight(cells, opts.highlight))
+	}
+	if opts.list {
+		for i, h := range hits {
+			fmt.Fprintf(w, "%4d: %s %v\n", i+1, h.desc, h.cells)
+		}
+	}
+}
@@ -2,109 +2,167 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
+
+	"github.com/floj/aoc2024/lib/geom"
 )
 
 const XMAS = "XMAS"
-const XMAS_REVERSE = "SAMX"
+
+type options struct {
+	input string
+	// words to look for instead of solving the puzzle
+	words []string
+	dirs  []Direction
+	wrap  bool
+	// file with templates to look for instead of solving the puzzle
+	templates string
+	// how to mark matched cells when printing the matrix, one of the highlight* constants
+	highlight string
+	// print every match with its coordinates and orientation
+	list bool
+	// read the grid line by line instead of loading it into memory
+	stream bool
+}
 
 func main() {
-	if err := run(); err != nil {
+	opts := options{}
+	words, dirs := "", ""
+	flag.StringVar(&opts.input, "input", "input.txt", "puzzle input file")
+	flag.StringVar(&words, "words", "", "comma separated list of words to search for instead of solving the puzzle")
+	flag.StringVar(&dirs, "dirs", "all", "directions to search -words in, either all or forward (left to right and top to bottom only)")
+	flag.StringVar(&opts.templates, "templates", "", "file with templates separated by empty lines to search for instead of solving the puzzle, '.' matches any letter")
+	flag.BoolVar(&opts.wrap, "wrap", false, "let words wrap around the borders of the grid when searching -words")
+	flag.StringVar(&opts.highlight, "highlight", highlightNone, "print the grid with matched cells highlighted, either ansi (colored) or case (matches upper case, everything else lower case)")
+	flag.BoolVar(&opts.list, "list", false, "print every match with its coordinates and orientation")
+	flag.BoolVar(&opts.stream, "stream", false, "search the grid while reading it, keeping only the rows needed by the longest pattern in memory")
+	flag.Parse()
+	if words != "" {
+		opts.words = strings.Split(words, ",")
+	}
+	switch dirs {
+	case "all":
+		opts.dirs = AllDirections
+	case "forward":
+		opts.dirs = ForwardDirections
+	default:
+		fmt.Fprintf(os.Stderr, "invalid directions %s\n", dirs)
+		os.Exit(1)
+	}
+	switch opts.highlight {
+	case highlightNone, highlightANSI, highlightCase:
+	default:
+		fmt.Fprintf(os.Stderr, "invalid highlight style %s\n", opts.highlight)
+		os.Exit(1)
+	}
+
+	if err := run(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "puzzle errored with %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run() error {
-	bytes, err := os.ReadFile("input.txt")
+func run(opts options) error {
+	if opts.stream {
+		return runStream(opts)
+	}
+
+	bytes, err := os.ReadFile(opts.input)
 	if err != nil {
 		return err
 	}
 
+	if len(opts.words) > 0 {
+		return searchWords(bytes, opts)
+	}
+	if opts.templates != "" {
+		return searchTemplates(bytes, opts)
+	}
+
 	// part 1
 	xmas, err := countXMAS(bytes[:])
 	if err != nil {
 		return err
 	}
 	fmt.Fprintf(os.Stdout, "occurences part 1: %d\n", xmas)
+	if opts.highlight != highlightNone || opts.list {
+		m := NewMatrix(bytes)
+		showHits(os.Stdout, m, wordHits(m, xmasSearch.Find(m)), opts)
+	}
 
 	// part 2
-	masX, err := countMASX(bytes[:])
+	crosses, err := countMASX(bytes[:])
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(os.Stdout, "occurences part 2: %d\n", masX)
+	fmt.Fprintf(os.Stdout, "occurences part 2: %d\n", crosses)
+	if opts.highlight != highlightNone || opts.list {
+		m := NewMatrix(bytes)
+		showHits(os.Stdout, m, templateHits(findTemplates(m, []rect{toRect(masX)})), opts)
+	}
 	return nil
 }
 
 func countMASX(input []byte) (int, error) {
-	patterns := []rect{
-		toRect(mas1),
-		toRect(mas2),
-		toRect(mas3),
-		toRect(mas4),
-	}
-
 	m := NewMatrix(input)
-	occurences := 0
-
-	for y := range m.NumRows() {
-		for x := range m.NumCols() {
-			r, ok := m.Rect(x, y, 3, 3)
-			if !ok {
-				continue
-			}
-			for _, p := range patterns {
-				if p.Matches(r) {
-					occurences++
-					break
-				}
-			}
-		}
-	}
-
-	return occurences, nil
+	return countTemplates(m, []rect{toRect(masX)}), nil
+}
 
+var xmasSearch = WordSearch{
+	Words: []string{XMAS},
+	Dirs:  AllDirections,
 }
 
 func countXMAS(input []byte) (int, error) {
-
 	m := NewMatrix(input)
-	occurences := 0
-
-	for y := range m.NumRows() {
-		for x := range m.NumCols() {
-			// horizontal
-			if seq, ok := m.Seq(len(XMAS), x, y, 1, 0); ok && isXMAS(seq) {
-				occurences++
-			}
-
-			// vertical
-			if seq, ok := m.Seq(len(XMAS), x, y, 0, 1); ok && isXMAS(seq) {
-				occurences++
-			}
-
-			// diagonal form top left to bottom right
-			if seq, ok := m.Seq(len(XMAS), x, y, 1, 1); ok && isXMAS(seq) {
-				occurences++
-			}
+	return xmasSearch.Count(m), nil
+}
 
-			// diagonal form top right to bottom left
-			if seq, ok := m.Seq(len(XMAS), x, y, -1, 1); ok && isXMAS(seq) {
-				occurences++
-			}
-		}
+func searchWords(input []byte, opts options) error {
+	m := NewMatrix(input)
+	ws := WordSearch{
+		Words: opts.words,
+		Dirs:  opts.dirs,
+		Wrap:  opts.wrap,
 	}
 
-	return occurences, nil
+	perWord := map[string]int{}
+	matches := ws.Find(m)
+	for _, ma := range matches {
+		perWord[ma.Word]++
+	}
+	for _, w := range opts.words {
+		fmt.Fprintf(os.Stdout, "occurences of %s: %d\n", w, perWord[w])
+	}
+	fmt.Fprintf(os.Stdout, "total occurences: %d\n", len(matches))
+	showHits(os.Stdout, m, wordHits(m, matches), opts)
+	return nil
 }
 
-func isXMAS(s string) bool {
-	return s == XMAS || s == XMAS_REVERSE
+func searchTemplates(input []byte, opts options) error {
+	templates, err := loadTemplates(opts.templates)
+	if err != nil {
+		return err
+	}
+	m := NewMatrix(input)
+	matches := findTemplates(m, templates)
+	perTemplate := make([]int, len(templates))
+	for _, tm := range matches {
+		perTemplate[tm.template]++
+	}
+	for i, t := range templates {
+		fmt.Fprintf(os.Stdout, "occurences of template %d (%d variants): %d\n", i+1, len(t.Variants()), perTemplate[i])
+	}
+	showHits(os.Stdout, m, templateHits(matches), opts)
+	return nil
 }
 
 func NewMatrix(s []byte) matrix {
+	// ignore trailing line breaks, so the last row is not empty
+	s = bytes.TrimRight(s, "\r\n")
 	data := bytes.Split(s, []byte("\n"))
 	return matrix(data)
 }
@@ -134,23 +192,26 @@ func (m matrix) Get(x, y int) (byte, bool) {
 	return row[x], true
 }
 
+// Wrap moves p into the bounds of the matrix as if it was placed on a torus.
+func (m matrix) Wrap(p geom.Vec) geom.Vec {
+	cols, rows := m.NumCols(), m.NumRows()
+	return geom.Vec{
+		X: ((p.X % cols) + cols) % cols,
+		Y: ((p.Y % rows) + rows) % rows,
+	}
+}
+
 type rect [][]byte
 
-func (r rect) Matches(other [][]byte) bool {
-	if len(r) != len(other) {
-		return false
-	}
-	for i := range other {
-		or := other[i]
-		rr := r[i]
-		if len(rr) != len(or) {
-			return false
-		}
-		for j := range rr {
-			if rr[j] == '.' {
-				continue
+// MatchesAt compares r to the cells of g starting at x,y without copying them, '.' in r matches any cell.
+func (r rect) MatchesAt(g grid, x, y int) bool {
+	for j, row := range r {
+		for i, b := range row {
+			v, ok := g.Get(x+i, y+j)
+			if !ok {
+				return false
 			}
-			if or[j] != rr[j] {
+			if b != '.' && v != b {
 				return false
 			}
 		}
@@ -171,62 +232,13 @@ func (r rect) String() string {
 	return buf.String()
 }
 
-func (m *matrix) Rect(x, y, w, h int) (rect, bool) {
-	r := make([][]byte, 0, h)
-	for ih := range h {
-		row := []byte{}
-		for iw := range w {
-			v, ok := m.Get(x+iw, y+ih)
-			if !ok {
-				return nil, false
-			}
-			row = append(row, v)
-		}
-		r = append(r, row)
-	}
-	return rect(r), true
-}
-
-func (m *matrix) Seq(len, startX, startY, offsetX, offsetY int) (string, bool) {
-	buf := bytes.Buffer{}
-	x, y := startX, startY
-	for range len {
-		v, ok := m.Get(x, y)
-		if !ok {
-			return "", false
-		}
-		buf.WriteByte(v)
-		x += offsetX
-		y += offsetY
-
-	}
-	return buf.String(), true
-}
-
-const mas1 = `
+// all rotations of the X-MAS shape are generated by rect.Variants
+const masX = `
 M.S
 .A.
 M.S
 `
 
-const mas2 = `
-S.M
-.A.
-S.M
-`
-
-const mas3 = `
-M.M
-.A.
-S.S
-`
-
-const mas4 = `
-S.S
-.A.
-M.M
-`
-
 func toRect(s string) rect {
 	b := []byte(s)
 	b = bytes.TrimSpace(b)
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+const example = `MMMSXXMASM
+MSAMXMSMSA
+AMXSXMAAMM
+MSAMASMSMX
+XMASAMXAMM
+XXAMMXXAMA
+SMSMSASXSS
+SAXAMASAAA
+MAMMMXMMMM
+MXMXAXMASX
+`
+
+func TestCount(t *testing.T) {
+	table := []struct {
+		name     string
+		count    func([]byte) (int, error)
+		expected int
+	}{
+		{name: "XMAS", count: countXMAS, expected: 18},
+		{name: "X-MAS", count: countMASX, expected: 9},
+	}
+	for _, td := range table {
+		t.Run(td.name, func(t *testing.T) {
+			got, err := td.count([]byte(example))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != td.expected {
+				t.Fatalf("expected %d, got %d", td.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"github.com/floj/aoc2024/lib/geom"
+)
+
+// Direction is the reading direction of a word in the matrix.
+type Direction struct {
+	Name string
+	Off  geom.Vec
+}
+
+func (d Direction) String() string {
+	return d.Name
+}
+
+var (
+	East      = Direction{Name: "E", Off: geom.Vec{X: 1, Y: 0}}
+	SouthEast = Direction{Name: "SE", Off: geom.Vec{X: 1, Y: 1}}
+	South     = Direction{Name: "S", Off: geom.Vec{X: 0, Y: 1}}
+	SouthWest = Direction{Name: "SW", Off: geom.Vec{X: -1, Y: 1}}
+	West      = Direction{Name: "W", Off: geom.Vec{X: -1, Y: 0}}
+	NorthWest = Direction{Name: "NW", Off: geom.Vec{X: -1, Y: -1}}
+	North     = Direction{Name: "N", Off: geom.Vec{X: 0, Y: -1}}
+	NorthEast = Direction{Name: "NE", Off: geom.Vec{X: 1, Y: -1}}
+)
+
+// AllDirections are all 8 directions a word can be written in, clockwise starting east.
+var AllDirections = []Direction{East, SouthEast, South, SouthWest, West, NorthWest, North, NorthEast}
+
+// ForwardDirections only contains the directions reading left to right or top to bottom.
+var ForwardDirections = []Direction{East, SouthEast, South, SouthWest}
+
+// Match is a word found in the matrix, starting at Pos and continuing into Dir.
+type Match struct {
+	Word string
+	Pos  geom.Vec
+	Dir  Direction
+}
+
+// Cells returns the positions of all letters of the match.
+// On wrapping searches the positions are normalized into the matrix bounds.
+func (ma Match) Cells(m matrix) []geom.Vec {
+	cells := make([]geom.Vec, 0, len(ma.Word))
+	p := ma.Pos
+	for range len(ma.Word) {
+		cells = append(cells, m.Wrap(p))
+		p = p.Add(ma.Dir.Off)
+	}
+	return cells
+}
+
+// WordSearch looks for Words written in any of Dirs.
+// If Wrap is set, words continue on the opposite side when crossing the border.
+type WordSearch struct {
+	Words []string
+	Dirs  []Direction
+	Wrap  bool
+}
+
+// Find scans the matrix once and returns all matches ordered by position.
+func (ws WordSearch) Find(m matrix) []Match {
+	matches := []Match{}
+	ws.scan(m, func(ma Match) {
+		matches = append(matches, ma)
+	})
+	return matches
+}
+
+// Count returns the number of matches without collecting them.
+func (ws WordSearch) Count(m matrix) int {
+	count := 0
+	ws.scan(m, func(Match) {
+		count++
+	})
+	return count
+}
+
+// wordsByFirst indexes the words by their first letter, so each cell only checks candidate words.
+func (ws WordSearch) wordsByFirst() *[256][]string {
+	byFirst := [256][]string{}
+	for _, w := range ws.Words {
+		if w == "" {
+			continue
+		}
+		byFirst[w[0]] = append(byFirst[w[0]], w)
+	}
+	return &byFirst
+}
+
+func (ws WordSearch) scan(m matrix, found func(Match)) {
+	byFirst := ws.wordsByFirst()
+
+	var g grid = m
+	if ws.Wrap {
+		g = torus{m}
+	}
+
+	for y, row := range m {
+		for x, b := range row {
+			for _, w := range byFirst[b] {
+				for _, d := range ws.Dirs {
+					if wordAt(g, w, x, y, d.Off) {
+						found(Match{Word: w, Pos: geom.Vec{X: x, Y: y}, Dir: d})
+					}
+				}
+			}
+		}
+	}
+}
+
+// grid gives access to the letters of a word search, e.g. a whole matrix or a window of it.
+type grid interface {
+	Get(x, y int) (byte, bool)
+}
+
+// torus is a matrix where coordinates wrap around the borders.
+type torus struct {
+	matrix
+}
+
+func (t torus) Get(x, y int) (byte, bool) {
+	p := t.Wrap(geom.Vec{X: x, Y: y})
+	return t.matrix.Get(p.X, p.Y)
+}
+
+// wordAt checks if w continues from x,y into the direction off.
+// The first letter is not checked, the caller already did.
+func wordAt(g grid, w string, x, y int, off geom.Vec) bool {
+	for i := 1; i < len(w); i++ {
+		x += off.X
+		y += off.Y
+		v, ok := g.Get(x, y)
+		if !ok || v != w[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/floj/aoc2024/lib/geom"
+)
+
+func TestFind(t *testing.T) {
+	table := []struct {
+		name     string
+		input    string
+		search   WordSearch
+		expected []Match
+	}{
+		{
+			name:     "east",
+			input:    "XMAS",
+			search:   WordSearch{Words: []string{XMAS}, Dirs: AllDirections},
+			expected: []Match{{Word: XMAS, Pos: geom.Vec{X: 0, Y: 0}, Dir: East}},
+		},
+		{
+			name:     "west",
+			input:    "SAMX",
+			search:   WordSearch{Words: []string{XMAS}, Dirs: AllDirections},
+			expected: []Match{{Word: XMAS, Pos: geom.Vec{X: 3, Y: 0}, Dir: West}},
+		},
+		{
+			name:     "forward only",
+			input:    "SAMX",
+			search:   WordSearch{Words: []string{XMAS}, Dirs: ForwardDirections},
+			expected: []Match{},
+		},
+		{
+			name:     "diagonal",
+			input:    "X...\n.M..\n..A.\n...S",
+			search:   WordSearch{Words: []string{XMAS}, Dirs: AllDirections},
+			expected: []Match{{Word: XMAS, Pos: geom.Vec{X: 0, Y: 0}, Dir: SouthEast}},
+		},
+		{
+			name:     "no wrap",
+			input:    "ASXM",
+			search:   WordSearch{Words: []string{XMAS}, Dirs: AllDirections},
+			expected: []Match{},
+		},
+		{
+			name:     "wrap east",
+			input:    "ASXM",
+			search:   WordSearch{Words: []string{XMAS}, Dirs: []Direction{East}, Wrap: true},
+			expected: []Match{{Word: XMAS, Pos: geom.Vec{X: 2, Y: 0}, Dir: East}},
+		},
+		{
+			name:     "wrap north",
+			input:    "M\nX\nS\nA",
+			search:   WordSearch{Words: []string{XMAS}, Dirs: []Direction{North}, Wrap: true},
+			expected: []Match{{Word: XMAS, Pos: geom.Vec{X: 0, Y: 1}, Dir: North}},
+		},
+	}
+	for _, td := range table {
+		t.Run(td.name, func(t *testing.T) {
+			got := td.search.Find(NewMatrix([]byte(td.input)))
+			if !slices.Equal(td.expected, got) {
+				t.Fatalf("expected %v, got %v", td.expected, got)
+			}
+		})
+	}
+}
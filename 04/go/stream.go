@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/floj/aoc2024/lib/geom"
+)
+
+// window keeps the last rows read from a stream, addressed by their absolute row number.
+type window struct {
+	rows [][]byte
+	// number of rows pushed so far
+	n int
+}
+
+func newWindow(size int) *window {
+	return &window{rows: make([][]byte, max(size, 1))}
+}
+
+func (w *window) Get(x, y int) (byte, bool) {
+	if x < 0 || y < 0 || y >= w.n || y <= w.n-1-len(w.rows) {
+		return 0, false
+	}
+	row := w.rows[y%len(w.rows)]
+	if x >= len(row) {
+		return 0, false
+	}
+	return row[x], true
+}
+
+// next returns the buffer for the next row, reusing the memory of the row that drops out of the window.
+func (w *window) next() []byte {
+	return w.rows[w.n%len(w.rows)][:0]
+}
+
+func (w *window) push(row []byte) {
+	w.rows[w.n%len(w.rows)] = row
+	w.n++
+}
+
+// streamSearch finds words and templates in a grid read line by line,
+// keeping only as many rows in memory as the highest word or template needs.
+// It reports the same matches as WordSearch.Find and findTemplates, but in a different order.
+// Wrapping words are not supported, as they need the whole grid.
+type streamSearch struct {
+	words     WordSearch
+	templates []rect
+}
+
+func (s streamSearch) height() int {
+	h := 1
+	for _, w := range s.words.Words {
+		h = max(h, len(w))
+	}
+	for _, t := range s.templates {
+		// rotated variants swap width and height
+		h = max(h, t.Width(), t.Height())
+	}
+	return h
+}
+
+// Run reads the grid from r and calls onWord and onTemplate for every match.
+func (s streamSearch) Run(r io.Reader, onWord func(Match), onTemplate func(templateMatch)) error {
+	variants := templateVariants(s.templates)
+
+	br := bufio.NewReader(r)
+	win := newWindow(s.height())
+	cols := -1
+	for {
+		row, err := readRow(br, win.next())
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if errors.Is(err, io.EOF) && len(row) == 0 {
+			return nil
+		}
+		win.push(row)
+		if cols < 0 {
+			// like matrix.NumCols, the width of the first row determines the template positions
+			cols = len(row)
+		}
+		bottom := win.n - 1
+
+		// every match is reported once its lowest row was read
+		for _, w := range s.words.Words {
+			if w == "" {
+				continue
+			}
+			for _, d := range s.words.Dirs {
+				y := bottom
+				if d.Off.Y > 0 {
+					y = bottom - (len(w) - 1)
+				}
+				if y < 0 {
+					continue
+				}
+				for x := 0; ; x++ {
+					b, ok := win.Get(x, y)
+					if !ok {
+						break
+					}
+					if b != w[0] {
+						continue
+					}
+					if wordAt(win, w, x, y, d.Off) {
+						onWord(Match{Word: w, Pos: geom.Vec{X: x, Y: y}, Dir: d})
+					}
+				}
+			}
+		}
+
+		for _, v := range variants {
+			y := bottom - (v.Height() - 1)
+			if y < 0 {
+				continue
+			}
+			for x := range cols {
+				if v.MatchesAt(win, x, y) {
+					onTemplate(templateMatch{template: v.template, variant: v.orientedRect, pos: geom.Vec{X: x, Y: y}})
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+}
+
+// readRow reads the next line into buf, without the line break.
+func readRow(br *bufio.Reader, buf []byte) ([]byte, error) {
+	for {
+		line, err := br.ReadSlice('\n')
+		buf = append(buf, line...)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if len(buf) > 0 && buf[len(buf)-1] == '\n' {
+			buf = buf[:len(buf)-1]
+		}
+		return buf, err
+	}
+}
+
+func runStream(opts options) error {
+	if opts.wrap || opts.highlight != highlightNone || opts.list {
+		return fmt.Errorf("-wrap, -highlight and -list need the whole grid and can't be combined with -stream")
+	}
+
+	s := streamSearch{}
+	switch {
+	case len(opts.words) > 0:
+		s.words = WordSearch{Words: opts.words, Dirs: opts.dirs}
+	case opts.templates != "":
+		templates, err := loadTemplates(opts.templates)
+		if err != nil {
+			return err
+		}
+		s.templates = templates
+	default:
+		s.words = xmasSearch
+		s.templates = []rect{toRect(masX)}
+	}
+
+	f, err := os.Open(opts.input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	perWord := map[string]int{}
+	perTemplate := make([]int, len(s.templates))
+	err = s.Run(f, func(ma Match) {
+		perWord[ma.Word]++
+	}, func(tm templateMatch) {
+		perTemplate[tm.template]++
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(opts.words) > 0:
+		total := 0
+		for _, w := range opts.words {
+			fmt.Fprintf(os.Stdout, "occurences of %s: %d\n", w, perWord[w])
+			total += perWord[w]
+		}
+		fmt.Fprintf(os.Stdout, "total occurences: %d\n", total)
+	case opts.templates != "":
+		for i, t := range s.templates {
+			fmt.Fprintf(os.Stdout, "occurences of template %d (%d variants): %d\n", i+1, len(t.Variants()), perTemplate[i])
+		}
+	default:
+		fmt.Fprintf(os.Stdout, "occurences part 1: %d\n", perWord[XMAS])
+		fmt.Fprintf(os.Stdout, "occurences part 2: %d\n", perTemplate[0])
+	}
+	return nil
+}
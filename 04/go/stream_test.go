@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func randomGrid(rnd *rand.Rand, cols, rows int) []byte {
+	b := bytes.Buffer{}
+	for range rows {
+		for range cols {
+			b.WriteByte("XMAS"[rnd.IntN(4)])
+		}
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+func TestStreamMatchesInMemory(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(4, 2024))
+	words := WordSearch{Words: []string{XMAS, "MAS", "SAMXS"}, Dirs: AllDirections}
+	templates := []rect{toRect(masX), toRect("XM..\n..AS")}
+
+	for i := range 20 {
+		in := randomGrid(rnd, 1+rnd.IntN(30), 1+rnd.IntN(30))
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			m := NewMatrix(in)
+			wantWords := []string{}
+			for _, ma := range words.Find(m) {
+				wantWords = append(wantWords, fmt.Sprint(ma))
+			}
+			wantTemplates := []string{}
+			for _, tm := range findTemplates(m, templates) {
+				wantTemplates = append(wantTemplates, fmt.Sprint(tm))
+			}
+
+			gotWords, gotTemplates := []string{}, []string{}
+			s := streamSearch{words: words, templates: templates}
+			err := s.Run(bytes.NewReader(in), func(ma Match) {
+				gotWords = append(gotWords, fmt.Sprint(ma))
+			}, func(tm templateMatch) {
+				gotTemplates = append(gotTemplates, fmt.Sprint(tm))
+			})
+			if err != nil {
+				t.Fatalf("stream search failed: %v", err)
+			}
+
+			slices.Sort(wantWords)
+			slices.Sort(gotWords)
+			if !slices.Equal(wantWords, gotWords) {
+				t.Fatalf("word matches differ:\nwant %v\ngot  %v", wantWords, gotWords)
+			}
+			slices.Sort(wantTemplates)
+			slices.Sort(gotTemplates)
+			if !slices.Equal(wantTemplates, gotTemplates) {
+				t.Fatalf("template matches differ:\nwant %v\ngot  %v", wantTemplates, gotTemplates)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/floj/aoc2024/lib/geom"
+)
+
+func (r rect) Width() int {
+	if len(r) == 0 {
+		return 0
+	}
+	return len(r[0])
+}
+
+func (r rect) Height() int {
+	return len(r)
+}
+
+func (r rect) Equal(o rect) bool {
+	return slices.EqualFunc(r, o, bytes.Equal)
+}
+
+// Rotate returns a copy of r rotated 90 degrees clockwise.
+func (r rect) Rotate() rect {
+	w, h := r.Width(), r.Height()
+	rot := make(rect, w)
+	for y := range w {
+		rot[y] = make([]byte, h)
+		for x := range h {
+			rot[y][x] = r[h-1-x][y]
+		}
+	}
+	return rot
+}
+
+// Mirror returns a copy of r reflected along its vertical axis.
+func (r rect) Mirror() rect {
+	m := make(rect, len(r))
+	for y, row := range r {
+		m[y] = slices.Clone(row)
+		slices.Reverse(m[y])
+	}
+	return m
+}
+
+// Variants returns all distinct rotations and reflections of r, starting with r itself.
+func (r rect) Variants() []rect {
+	variants := []rect{}
+	for _, o := range r.orientations() {
+		variants = append(variants, o.rect)
+	}
+	return variants
+}
+
+// orientedRect is a variant of a template together with how it was derived from the template.
+type orientedRect struct {
+	rect
+	// clockwise rotation in degrees, applied after mirroring
+	rotation int
+	mirrored bool
+}
+
+func (o orientedRect) Orientation() string {
+	s := fmt.Sprintf("rotated %d°", o.rotation)
+	if o.mirrored {
+		s += ", mirrored"
+	}
+	return s
+}
+
+func (r rect) orientations() []orientedRect {
+	variants := []orientedRect{}
+	add := func(v orientedRect) {
+		if !slices.ContainsFunc(variants, func(e orientedRect) bool { return e.Equal(v.rect) }) {
+			variants = append(variants, v)
+		}
+	}
+	cur, mirrored := r, r.Mirror()
+	for i := range 4 {
+		add(orientedRect{rect: cur, rotation: i * 90})
+		add(orientedRect{rect: mirrored, rotation: i * 90, mirrored: true})
+		cur, mirrored = cur.Rotate(), mirrored.Rotate()
+	}
+	return variants
+}
+
+// templateMatch is an occurence of a template variant in the matrix.
+type templateMatch struct {
+	// index of the matched template
+	template int
+	variant  orientedRect
+	pos      geom.Vec
+}
+
+// Cells returns the positions of all non wildcard letters of the match.
+func (tm templateMatch) Cells() []geom.Vec {
+	cells := []geom.Vec{}
+	for y, row := range tm.variant.rect {
+		for x, b := range row {
+			if b != '.' {
+				cells = append(cells, tm.pos.Add(geom.Vec{X: x, Y: y}))
+			}
+		}
+	}
+	return cells
+}
+
+// findTemplates returns the occurences of all variants of all templates in the matrix.
+// Different variants matching at the same position are reported separately.
+func findTemplates(m matrix, templates []rect) []templateMatch {
+	variants := templateVariants(templates)
+
+	matches := []templateMatch{}
+	for y := range m.NumRows() {
+		for x := range m.NumCols() {
+			for _, v := range variants {
+				if v.MatchesAt(m, x, y) {
+					matches = append(matches, templateMatch{template: v.template, variant: v.orientedRect, pos: geom.Vec{X: x, Y: y}})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+type templateVariant struct {
+	template int
+	orientedRect
+}
+
+func templateVariants(templates []rect) []templateVariant {
+	variants := []templateVariant{}
+	for i, t := range templates {
+		for _, o := range t.orientations() {
+			variants = append(variants, templateVariant{template: i, orientedRect: o})
+		}
+	}
+	return variants
+}
+
+// countTemplates counts the occurences of all variants of all templates in the matrix.
+func countTemplates(m matrix, templates []rect) int {
+	return len(findTemplates(m, templates))
+}
+
+// loadTemplates reads templates separated by empty lines from file.
+// Like in the built in templates, '.' matches any letter.
+func loadTemplates(file string) ([]rect, error) {
+	in, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []rect{}
+	for _, block := range bytes.Split(bytes.ReplaceAll(in, []byte("\r\n"), []byte("\n")), []byte("\n\n")) {
+		block = bytes.TrimSpace(block)
+		if len(block) == 0 {
+			continue
+		}
+		t := toRect(string(block))
+		for _, row := range t {
+			if len(row) != t.Width() {
+				return nil, fmt.Errorf("template %d in %s is not rectangular:\n%s", len(templates)+1, file, t)
+			}
+		}
+		templates = append(templates, t)
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", file)
+	}
+	return templates, nil
+}
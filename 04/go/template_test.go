@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVariants(t *testing.T) {
+	table := []struct {
+		name     string
+		template string
+		expected int
+	}{
+		{name: "X-MAS", template: masX, expected: 4},
+		{name: "asymmetric", template: "XM..\n..AS", expected: 8},
+		{name: "symmetric", template: "M.M\n.A.\nM.M", expected: 1},
+	}
+	for _, td := range table {
+		t.Run(td.name, func(t *testing.T) {
+			if got := len(toRect(td.template).Variants()); got != td.expected {
+				t.Fatalf("expected %d variants, got %d", td.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	templates, err := loadTemplates(write("ok.txt", "\n\nM.S\n.A.\nM.S\n\n\n\nXMAS\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 2 || templates[1].String() != "XMAS" {
+		t.Fatalf("expected 2 templates, got %v", templates)
+	}
+
+	// the empty blocks in front don't count, the broken template is the second one
+	_, err = loadTemplates(write("broken.txt", "\n\nMAS\n\n\n\nXM\nX\n"))
+	if err == nil {
+		t.Fatal("expected error for non-rectangular template")
+	}
+	if !strings.Contains(err.Error(), "template 2 ") || !strings.Contains(err.Error(), "not rectangular") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+)
+
+// Violation is a rule broken by an update, with the indices of both pages in the update.
+type Violation struct {
+	Rule
+	beforeIdx int
+	behindIdx int
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s (%d at index %d, %d at index %d)", v.Rule, v.before, v.beforeIdx, v.behind, v.behindIdx)
+}
+
+// positions maps each page of the update to the index of its first occurrence.
+func (u update) positions() map[int]int {
+	pos := make(map[int]int, len(u))
+	for i, p := range u {
+		if _, ok := pos[p]; !ok {
+			pos[p] = i
+		}
+	}
+	return pos
+}
+
+// Violations returns all rules the update breaks, ordered by the position of their before page in the update.
+func (u update) Violations(g ruleGraph) []Violation {
+	return slices.Collect(u.violations(g))
+}
+
+// violations yields the rules the update breaks, ordered by the position of their before page
+// in the update. Stopping early skips checking the remaining rules.
+func (u update) violations(g ruleGraph) iter.Seq[Violation] {
+	return func(yield func(Violation) bool) {
+		pos := u.positions()
+		for beforeIdx, p := range u {
+			for _, b := range g.Behind(p) {
+				behindIdx, ok := pos[b]
+				if !ok {
+					// page not in update, ignore
+					continue
+				}
+				if beforeIdx >= behindIdx && !yield(Violation{Rule: Rule{before: p, behind: b}, beforeIdx: beforeIdx, behindIdx: behindIdx}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeReport explains for every incorrect update which rules it breaks.
+func (in Input) writeReport(w io.Writer) {
+	for i, u := range in.updates {
+		violations := u.Violations(in.graph)
+		if len(violations) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "update %d %v breaks %d rules:\n", i+1, u, len(violations))
+		for _, v := range violations {
+			fmt.Fprintf(w, "  %s\n", v)
+		}
+	}
+}
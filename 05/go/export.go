@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+const (
+	formatDOT     = "dot"
+	formatMermaid = "mermaid"
+)
+
+// ruleSubgraph returns the pages of u and all rules between them.
+// If u is nil, all pages and rules are returned.
+func (in Input) ruleSubgraph(u update) ([]int, []Rule) {
+	if u == nil {
+		pages := []int{}
+		for _, r := range in.rules {
+			pages = append(pages, r.before, r.behind)
+		}
+		slices.Sort(pages)
+		return slices.Compact(pages), slices.Clone(in.rules)
+	}
+
+	pos := u.positions()
+	rules := []Rule{}
+	for _, r := range in.rules {
+		_, beforeOk := pos[r.before]
+		_, behindOk := pos[r.behind]
+		if beforeOk && behindOk {
+			rules = append(rules, r)
+		}
+	}
+	return slices.Clone(u), rules
+}
+
+// reduceRules drops every rule that is implied by the remaining rules,
+// e.g. 47|61 is dropped if 47|53 and 53|61 exist.
+// Rules are checked one after another against the already reduced set, so reachability
+// between pages is kept even if the rules contain cycles.
+func reduceRules(rules []Rule) []Rule {
+	// duplicates would not be dropped, as every copy skips the direct rule only
+	unique := make([]Rule, 0, len(rules))
+	seen := map[Rule]bool{}
+	for _, r := range rules {
+		if !seen[r] {
+			seen[r] = true
+			unique = append(unique, r)
+		}
+	}
+	rules = unique
+
+	behind := map[int][]int{}
+	for _, r := range rules {
+		behind[r.before] = append(behind[r.before], r.behind)
+	}
+
+	// reachable checks if to can be reached from from without using the direct rule from|to
+	reachable := func(from, to int) bool {
+		seen := map[int]bool{from: true}
+		queue := []int{from}
+		for len(queue) > 0 {
+			p := queue[0]
+			queue = queue[1:]
+			for _, b := range behind[p] {
+				if p == from && b == to {
+					continue
+				}
+				if b == to {
+					return true
+				}
+				if !seen[b] {
+					seen[b] = true
+					queue = append(queue, b)
+				}
+			}
+		}
+		return false
+	}
+
+	reduced := []Rule{}
+	for _, r := range rules {
+		if reachable(r.before, r.behind) {
+			behind[r.before] = slices.DeleteFunc(behind[r.before], func(b int) bool { return b == r.behind })
+			continue
+		}
+		reduced = append(reduced, r)
+	}
+	return reduced
+}
+
+func writeDOT(w io.Writer, pages []int, rules []Rule) {
+	fmt.Fprintln(w, "digraph rules {")
+	for _, p := range pages {
+		fmt.Fprintf(w, "\t%d;\n", p)
+	}
+	for _, r := range rules {
+		fmt.Fprintf(w, "\t%d -> %d;\n", r.before, r.behind)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func writeMermaid(w io.Writer, pages []int, rules []Rule) {
+	fmt.Fprintln(w, "flowchart LR")
+	for _, p := range pages {
+		fmt.Fprintf(w, "\tp%d[%d]\n", p, p)
+	}
+	for _, r := range rules {
+		fmt.Fprintf(w, "\tp%d --> p%d\n", r.before, r.behind)
+	}
+}
+
+// exportRules writes the rules, optionally restricted to the pages of one update (1 based, 0 for all),
+// as graph in the given format.
+func (in Input) exportRules(w io.Writer, format string, updateNum int, reduce bool) error {
+	var u update
+	if updateNum > 0 {
+		if updateNum > len(in.updates) {
+			return fmt.Errorf("update %d does not exist, only %d updates found", updateNum, len(in.updates))
+		}
+		u = in.updates[updateNum-1]
+	}
+
+	pages, rules := in.ruleSubgraph(u)
+	if reduce {
+		rules = reduceRules(rules)
+	}
+
+	switch format {
+	case formatDOT:
+		writeDOT(w, pages, rules)
+	case formatMermaid:
+		writeMermaid(w, pages, rules)
+	default:
+		return fmt.Errorf("unknown export format %s", format)
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+module github.com/floj/aoc2024/05/go
+
+go 1.23.4
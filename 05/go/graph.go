@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ruleGraph is the directed graph of all page ordering rules, with an edge from before to behind.
+// It indexes the rules in both directions and backs checking, ordering and querying updates.
+// Pages are numbered densely, so the adjacency lists and per update state can be plain slices.
+type ruleGraph struct {
+	ids   map[int]int
+	pages []int
+	// pages that must come behind / ahead of a page, in the order of the rules
+	behind [][]int
+	ahead  [][]int
+}
+
+func newRuleGraph(rules []Rule) ruleGraph {
+	g := ruleGraph{ids: map[int]int{}}
+	for _, r := range rules {
+		before, behind := g.id(r.before), g.id(r.behind)
+		g.behind[before] = append(g.behind[before], behind)
+		g.ahead[behind] = append(g.ahead[behind], before)
+	}
+	return g
+}
+
+// Behind returns the pages that must be placed behind page.
+func (g ruleGraph) Behind(page int) []int {
+	return g.lookup(page, g.behind)
+}
+
+// Ahead returns the pages that must be placed ahead of page.
+func (g ruleGraph) Ahead(page int) []int {
+	return g.lookup(page, g.ahead)
+}
+
+func (g ruleGraph) lookup(page int, adj [][]int) []int {
+	id, ok := g.ids[page]
+	if !ok {
+		return nil
+	}
+	pages := make([]int, 0, len(adj[id]))
+	for _, p := range adj[id] {
+		pages = append(pages, g.pages[p])
+	}
+	return pages
+}
+
+func (g *ruleGraph) id(page int) int {
+	if id, ok := g.ids[page]; ok {
+		return id
+	}
+	id := len(g.pages)
+	g.ids[page] = id
+	g.pages = append(g.pages, page)
+	g.behind = append(g.behind, nil)
+	g.ahead = append(g.ahead, nil)
+	return id
+}
+
+// Order sorts the pages of u topologically using Kahn's algorithm on the rules between them.
+// Pages without any rules come first, the others are placed in the order they become
+// unconstrained, starting with the order they have in u, which keeps the result deterministic.
+// Pages with rules must not appear more than once.
+func (g ruleGraph) Order(u update) (update, error) {
+	// number of unplaced pages of the update each page has to be placed behind,
+	// -1 for pages not in the update
+	inDegree := make([]int, len(g.pages))
+	for i := range inDegree {
+		inDegree[i] = -1
+	}
+	ordered := make(update, 0, len(u))
+	ids := make([]int, 0, len(u))
+	for _, p := range u {
+		id, ok := g.ids[p]
+		if !ok {
+			// page without any rules, can go anywhere
+			ordered = append(ordered, p)
+			continue
+		}
+		if inDegree[id] == 0 {
+			// the rules can't say which of both copies goes where
+			return nil, &DuplicatePageError{Update: u, Page: p}
+		}
+		inDegree[id] = 0
+		ids = append(ids, id)
+	}
+	for _, id := range ids {
+		for _, b := range g.behind[id] {
+			if inDegree[b] >= 0 {
+				inDegree[b]++
+			}
+		}
+	}
+
+	ready := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if inDegree[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, g.pages[id])
+		for _, b := range g.behind[id] {
+			if inDegree[b] <= 0 {
+				continue
+			}
+			inDegree[b]--
+			if inDegree[b] == 0 {
+				ready = append(ready, b)
+			}
+		}
+	}
+
+	if len(ordered) != len(u) {
+		// all pages that could not be placed are part of or behind a cycle
+		if cycle := g.findCycle(ids, inDegree); len(cycle) > 0 {
+			return ordered, &CycleError{Update: u, Cycle: cycle}
+		}
+		return ordered, fmt.Errorf("could only place %d of %d pages of %v", len(ordered), len(u), u)
+	}
+	return ordered, nil
+}
+
+// DuplicatePageError is returned if a page with rules appears more than once in an update.
+type DuplicatePageError struct {
+	Update update
+	Page   int
+}
+
+func (e *DuplicatePageError) Error() string {
+	return fmt.Sprintf("page %d appears more than once in %v", e.Page, e.Update)
+}
+
+// CycleError is returned if the rules for the pages of an update contradict each other.
+type CycleError struct {
+	Update update
+	// rules forming the cycle, each rule's behind page is the next rule's before page
+	Cycle []Rule
+}
+
+func (e *CycleError) Error() string {
+	rules := make([]string, 0, len(e.Cycle))
+	for _, r := range e.Cycle {
+		rules = append(rules, r.String())
+	}
+	return fmt.Sprintf("rules for %v contain a cycle: %s", e.Update, strings.Join(rules, ", "))
+}
+
+// findCycle searches the pages left over by Kahn's algorithm (inDegree > 0) for a cycle
+// using a depth first search, starting with the pages in update order.
+func (g ruleGraph) findCycle(ids, inDegree []int) []Rule {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make([]int, len(g.pages))
+	path := []int{}
+
+	var visit func(id int) []int
+	visit = func(id int) []int {
+		state[id] = onPath
+		path = append(path, id)
+		for _, b := range g.behind[id] {
+			if inDegree[b] <= 0 {
+				continue
+			}
+			switch state[b] {
+			case onPath:
+				// back edge, the path from b to id closes the cycle
+				for i, p := range path {
+					if p == b {
+						return path[i:]
+					}
+				}
+			case unvisited:
+				if c := visit(b); c != nil {
+					return c
+				}
+			}
+		}
+		state[id] = done
+		path = path[:len(path)-1]
+		return nil
+	}
+
+	for _, id := range ids {
+		if inDegree[id] <= 0 || state[id] != unvisited {
+			continue
+		}
+		c := visit(id)
+		if c == nil {
+			continue
+		}
+		// start the cycle at its lowest page, so the same cycle is always reported the same way
+		first := 0
+		for i, p := range c {
+			if g.pages[p] < g.pages[c[first]] {
+				first = i
+			}
+		}
+		rules := make([]Rule, 0, len(c))
+		for i := range c {
+			p, next := c[(first+i)%len(c)], c[(first+i+1)%len(c)]
+			rules = append(rules, Rule{before: g.pages[p], behind: g.pages[next]})
+		}
+		return rules
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"slices"
@@ -9,19 +10,57 @@ import (
 	"strings"
 )
 
+type options struct {
+	input string
+	// print which rules each incorrect update breaks
+	report bool
+	// export the rules as graph in this format instead of solving the puzzle
+	export string
+	// only export the rules between the pages of this update, 1 based
+	exportUpdate int
+	// drop rules implied by other rules when exporting
+	reduce bool
+	// print the pages that must come before and after this page instead of solving the puzzle
+	query int
+	// only consider the pages of this update when querying, 1 based
+	queryUpdate int
+}
+
 func main() {
-	if err := run(); err != nil {
+	opts := options{}
+	flag.StringVar(&opts.input, "input", "input.txt", "puzzle input file")
+	flag.BoolVar(&opts.report, "report", false, "print the rules each incorrect update breaks")
+	flag.StringVar(&opts.export, "export", "", "print the rules as graph instead of solving the puzzle, either dot or mermaid")
+	flag.IntVar(&opts.exportUpdate, "export-update", 0, "only export the rules between the pages of this update (1 based)")
+	flag.BoolVar(&opts.reduce, "reduce", false, "leave out rules implied by other rules when exporting")
+	flag.IntVar(&opts.query, "query", 0, "print the pages that must come before and after this page instead of solving the puzzle")
+	flag.IntVar(&opts.queryUpdate, "query-update", 0, "only consider the pages of this update (1 based) when querying")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "puzzle errored with %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run() error {
-	in, err := loadInput("input.txt")
+func run(opts options) error {
+	in, err := loadInput(opts.input)
 	if err != nil {
 		return err
 	}
 
+	if opts.export != "" {
+		return in.exportRules(os.Stdout, opts.export, opts.exportUpdate, opts.reduce)
+	}
+
+	if opts.query > 0 {
+		return in.query(os.Stdout, opts.query, opts.queryUpdate)
+	}
+
+	if opts.report {
+		in.writeReport(os.Stdout)
+	}
+
 	correct := in.sumCorrectUpdates()
 	fmt.Fprintf(os.Stdout, "correct updates middle page sum: %d\n", correct)
 
@@ -37,7 +76,7 @@ func run() error {
 func (in Input) sumIncorrectUpdates() (int, error) {
 	sum := 0
 	for _, u := range in.incorrectUpdates() {
-		ordered, err := u.orderByRules(in.rules)
+		ordered, err := in.graph.Order(u)
 		if err != nil {
 			return -1, fmt.Errorf("failed to order %v: %w", u, err)
 		}
@@ -46,6 +85,7 @@ func (in Input) sumIncorrectUpdates() (int, error) {
 	return sum, nil
 }
 
+// orderByRules is the initial implementation of ruleGraph.Order, only kept to benchmark against.
 func (u update) orderByRules(rules []Rule) (update, error) {
 	remaining := u[:]
 	ordered := update{}
@@ -102,12 +142,13 @@ func buildConstraints(pages []int, rules []Rule) map[int][]int {
 type Input struct {
 	rules   []Rule
 	updates []update
+	graph   ruleGraph
 }
 
 func (in Input) sumCorrectUpdates() int {
 	sum := 0
 	for _, u := range in.updates {
-		middlePage, ok := u.Check(in.rules)
+		middlePage, ok := u.Check(in.graph)
 		if !ok {
 			continue
 		}
@@ -117,22 +158,20 @@ func (in Input) sumCorrectUpdates() int {
 	return sum
 }
 
+// findBeforeRules returns the rules requiring page to be placed before other pages.
 func (in Input) findBeforeRules(page int) []Rule {
 	rules := []Rule{}
-	for _, r := range in.rules {
-		if r.before == page {
-			rules = append(rules, r)
-		}
+	for _, b := range in.graph.Behind(page) {
+		rules = append(rules, Rule{before: page, behind: b})
 	}
 	return rules
 }
 
+// findBehindRules returns the rules requiring page to be placed behind other pages.
 func (in Input) findBehindRules(page int) []Rule {
 	rules := []Rule{}
-	for _, r := range in.rules {
-		if r.behind == page {
-			rules = append(rules, r)
-		}
+	for _, b := range in.graph.Ahead(page) {
+		rules = append(rules, Rule{before: b, behind: page})
 	}
 	return rules
 }
@@ -140,7 +179,7 @@ func (in Input) findBehindRules(page int) []Rule {
 func (in Input) incorrectUpdates() []update {
 	incorrect := []update{}
 	for _, u := range in.updates {
-		if _, ok := u.Check(in.rules); ok {
+		if _, ok := u.Check(in.graph); ok {
 			continue
 		}
 		incorrect = append(incorrect, u)
@@ -151,17 +190,9 @@ func (in Input) incorrectUpdates() []update {
 
 type update []int
 
-func (u update) Check(rules []Rule) (int, bool) {
-	for _, r := range rules {
-		beforeIdx := slices.Index(u, r.before)
-		behindIdx := slices.Index(u, r.behind)
-		if beforeIdx < 0 || behindIdx < 0 {
-			// page not in update, ignore
-			continue
-		}
-		if beforeIdx >= behindIdx {
-			return -1, false
-		}
+func (u update) Check(g ruleGraph) (int, bool) {
+	for range u.violations(g) {
+		return -1, false
 	}
 	return u[len(u)/2], true
 }
@@ -183,6 +214,10 @@ type Rule struct {
 	behind int
 }
 
+func (r Rule) String() string {
+	return fmt.Sprintf("%d|%d", r.before, r.behind)
+}
+
 func newRule(s string) (Rule, error) {
 	parts := strings.Split(s, "|")
 	if len(parts) != 2 {
@@ -227,6 +262,8 @@ func loadInput(file string) (Input, error) {
 	if err := scanner.Err(); err != nil {
 		return i, err
 	}
+	i.graph = newRuleGraph(i.rules)
+
 	for scanner.Scan() {
 		line := scanner.Text()
 		u, err := newUpdate(line)
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// generateInput creates rules for every pair of pages out of a random total order,
+// like the puzzle input does, and updates with random subsets of these pages.
+func generateInput(rnd *rand.Rand, pages, updates int) Input {
+	order := rnd.Perm(pages)
+	in := Input{}
+	for i := range order {
+		for j := i + 1; j < len(order); j++ {
+			in.rules = append(in.rules, Rule{before: order[i] + 10, behind: order[j] + 10})
+		}
+	}
+	rnd.Shuffle(len(in.rules), func(i, j int) {
+		in.rules[i], in.rules[j] = in.rules[j], in.rules[i]
+	})
+	in.graph = newRuleGraph(in.rules)
+
+	for range updates {
+		perm := rnd.Perm(pages)
+		u := update{}
+		for _, p := range perm[:5+rnd.IntN(pages-5)] {
+			u = append(u, p+10)
+		}
+		in.updates = append(in.updates, u)
+	}
+	return in
+}
+
+func TestOrder(t *testing.T) {
+	in := generateInput(rand.New(rand.NewPCG(5, 2024)), 49, 200)
+	for _, u := range in.updates {
+		ordered, err := in.graph.Order(u)
+		if err != nil {
+			t.Fatalf("failed to order %v: %v", u, err)
+		}
+		if _, ok := ordered.Check(in.graph); !ok {
+			t.Fatalf("ordered update %v violates rules", ordered)
+		}
+		old, err := u.orderByRules(in.rules)
+		if err != nil {
+			t.Fatalf("failed to order %v with old implementation: %v", u, err)
+		}
+		if !slices.Equal(old, ordered) {
+			t.Fatalf("implementations disagree: %v vs %v", old, ordered)
+		}
+	}
+}
+
+func BenchmarkOrder(b *testing.B) {
+	in := generateInput(rand.New(rand.NewPCG(5, 2024)), 49, 200)
+	b.Run("kahn", func(b *testing.B) {
+		for range b.N {
+			for _, u := range in.updates {
+				if _, err := in.graph.Order(u); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("orderByRules", func(b *testing.B) {
+		for range b.N {
+			for _, u := range in.updates {
+				if _, err := u.orderByRules(in.rules); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func TestOrderCycle(t *testing.T) {
+	rules := []Rule{
+		{before: 97, behind: 47},
+		{before: 47, behind: 53},
+		{before: 53, behind: 61},
+		{before: 61, behind: 47},
+		{before: 61, behind: 13},
+	}
+	g := newRuleGraph(rules)
+	_, err := g.Order(update{13, 61, 97, 53, 47})
+
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	expected := []Rule{rules[1], rules[2], rules[3]}
+	if !slices.Equal(cycleErr.Cycle, expected) {
+		t.Fatalf("expected cycle %v, got %v", expected, cycleErr.Cycle)
+	}
+	if msg := cycleErr.Error(); !strings.HasSuffix(msg, "47|53, 53|61, 61|47") {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+}
+
+func TestOrderDuplicatePage(t *testing.T) {
+	g := newRuleGraph([]Rule{{before: 47, behind: 53}})
+	_, err := g.Order(update{47, 53, 53})
+
+	var dupErr *DuplicatePageError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("expected duplicate page error, got %v", err)
+	}
+	if dupErr.Page != 53 {
+		t.Fatalf("expected duplicate page 53, got %d", dupErr.Page)
+	}
+
+	// pages without rules can go anywhere, even twice
+	ordered, err := g.Order(update{53, 12, 47, 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (update{12, 12, 47, 53}); !slices.Equal(ordered, expected) {
+		t.Fatalf("expected %v, got %v", expected, ordered)
+	}
+}
+
+const exampleRules = `47|53
+97|13
+97|61
+97|47
+75|29
+61|13
+75|53
+29|13
+97|29
+53|29
+61|53
+97|53
+61|29
+47|13
+75|47
+97|75
+47|61
+75|61
+47|29
+75|13
+53|13`
+
+func exampleGraph(t *testing.T) ruleGraph {
+	rules := []Rule{}
+	for _, line := range strings.Split(exampleRules, "\n") {
+		r, err := newRule(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules = append(rules, r)
+	}
+	return newRuleGraph(rules)
+}
+
+func TestViolations(t *testing.T) {
+	g := exampleGraph(t)
+	table := []struct {
+		u        update
+		expected []Violation
+	}{
+		{u: update{75, 47, 61, 53, 29}, expected: nil},
+		{u: update{75, 97, 47, 61, 53}, expected: []Violation{
+			{Rule: Rule{before: 97, behind: 75}, beforeIdx: 1, behindIdx: 0},
+		}},
+		{u: update{61, 13, 29}, expected: []Violation{
+			{Rule: Rule{before: 29, behind: 13}, beforeIdx: 2, behindIdx: 1},
+		}},
+		{u: update{97, 13, 75, 29, 47}, expected: []Violation{
+			{Rule: Rule{before: 75, behind: 13}, beforeIdx: 2, behindIdx: 1},
+			{Rule: Rule{before: 29, behind: 13}, beforeIdx: 3, behindIdx: 1},
+			{Rule: Rule{before: 47, behind: 13}, beforeIdx: 4, behindIdx: 1},
+			{Rule: Rule{before: 47, behind: 29}, beforeIdx: 4, behindIdx: 3},
+		}},
+		// duplicate pages are compared with their first occurrence
+		{u: update{53, 47, 53}, expected: []Violation{
+			{Rule: Rule{before: 47, behind: 53}, beforeIdx: 1, behindIdx: 0},
+		}},
+	}
+	for _, td := range table {
+		got := td.u.Violations(g)
+		if !slices.Equal(td.expected, got) {
+			t.Errorf("%v: expected %v, got %v", td.u, td.expected, got)
+		}
+		if _, ok := td.u.Check(g); ok != (len(td.expected) == 0) {
+			t.Errorf("%v: Check returned %v with %d violations", td.u, ok, len(got))
+		}
+	}
+}
+
+func TestReduceRules(t *testing.T) {
+	table := []struct {
+		name     string
+		rules    []Rule
+		expected []Rule
+	}{
+		{
+			name:     "transitive",
+			rules:    []Rule{{before: 47, behind: 53}, {before: 53, behind: 61}, {before: 47, behind: 61}},
+			expected: []Rule{{before: 47, behind: 53}, {before: 53, behind: 61}},
+		},
+		{
+			name:     "implied first",
+			rules:    []Rule{{before: 47, behind: 61}, {before: 47, behind: 53}, {before: 53, behind: 61}},
+			expected: []Rule{{before: 47, behind: 53}, {before: 53, behind: 61}},
+		},
+		{
+			name:     "duplicates",
+			rules:    []Rule{{before: 47, behind: 53}, {before: 53, behind: 61}, {before: 47, behind: 53}},
+			expected: []Rule{{before: 47, behind: 53}, {before: 53, behind: 61}},
+		},
+		{
+			name:     "cycle",
+			rules:    []Rule{{before: 1, behind: 2}, {before: 2, behind: 3}, {before: 3, behind: 1}, {before: 1, behind: 3}},
+			expected: []Rule{{before: 1, behind: 2}, {before: 2, behind: 3}, {before: 3, behind: 1}},
+		},
+	}
+	for _, td := range table {
+		t.Run(td.name, func(t *testing.T) {
+			if got := reduceRules(td.rules); !slices.Equal(td.expected, got) {
+				t.Fatalf("expected %v, got %v", td.expected, got)
+			}
+		})
+	}
+}
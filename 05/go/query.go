@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// query prints which pages must come before and after page, according to the rules.
+// If updateNum is > 0, only the pages of that update (1 based) are considered.
+func (in Input) query(w io.Writer, page, updateNum int) error {
+	var pos map[int]int
+	if updateNum > 0 {
+		if updateNum > len(in.updates) {
+			return fmt.Errorf("update %d does not exist, only %d updates found", updateNum, len(in.updates))
+		}
+		u := in.updates[updateNum-1]
+		pos = u.positions()
+		if _, ok := pos[page]; !ok {
+			return fmt.Errorf("page %d is not part of update %d %v", page, updateNum, u)
+		}
+		fmt.Fprintf(w, "page %d in update %d %v\n", page, updateNum, u)
+	} else {
+		fmt.Fprintf(w, "page %d\n", page)
+	}
+
+	inUpdate := func(p int) bool {
+		if pos == nil {
+			return true
+		}
+		_, ok := pos[p]
+		return ok
+	}
+
+	ahead := []int{}
+	for _, r := range in.findBehindRules(page) {
+		if inUpdate(r.before) {
+			ahead = append(ahead, r.before)
+		}
+	}
+	behind := []int{}
+	for _, r := range in.findBeforeRules(page) {
+		if inUpdate(r.behind) {
+			behind = append(behind, r.behind)
+		}
+	}
+	fmt.Fprintf(w, "  must come after:  %v\n", ahead)
+	fmt.Fprintf(w, "  must come before: %v\n", behind)
+	return nil
+}
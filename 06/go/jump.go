@@ -0,0 +1,80 @@
+package main
+
+import (
+	"github.com/floj/aoc2024/lib/geom"
+)
+
+// jumpTable stores for every cell and direction where a guard walking straight ends up:
+// the index of the last free cell before the next obstacle, or -1 if the guard leaves the field.
+// It lets loop checks jump from turn to turn instead of walking cell by cell.
+type jumpTable struct {
+	cols, rows int
+	stop       [len(geom.Dirs)][]int
+}
+
+func newJumpTable(a *Area) *jumpTable {
+	t := &jumpTable{cols: a.cols, rows: a.rows}
+	for _, d := range geom.Dirs {
+		stop := make([]int, len(a.fields))
+		off := d.Vec()
+		// visit the cells so the neighbor in direction d is always done first
+		for i := range stop {
+			idx := i
+			if d == geom.South || d == geom.East {
+				idx = len(stop) - 1 - i
+			}
+			p := geom.FromIndex(idx, a.cols)
+			n := p.Add(off)
+			switch {
+			case !n.In(a.cols, a.rows):
+				stop[idx] = -1
+			case a.Get(n.X, n.Y) == '#':
+				stop[idx] = idx
+			default:
+				stop[idx] = stop[n.Index(a.cols)]
+			}
+		}
+		t.stop[d] = stop
+	}
+	return t
+}
+
+// next returns where the guard at pos heading into dir stops, taking the additional obstacle into account.
+// The second return value is false if the guard leaves the field.
+func (t *jumpTable) next(pos geom.Vec, dir geom.Dir, obstacle geom.Vec) (geom.Vec, bool) {
+	s := t.stop[dir][pos.Index(t.cols)]
+
+	// distance to the obstacle of the base map, beyond the border if there is none
+	baseDist := t.cols + t.rows
+	if s >= 0 {
+		baseDist = pos.Manhattan(geom.FromIndex(s, t.cols)) + 1
+	}
+
+	// patch the table for the additional obstacle, if it lies on the way
+	d := obstacle.Sub(pos)
+	if k := pos.Manhattan(obstacle); k > 0 && k < baseDist && d == dir.Vec().Mul(k) {
+		return pos.Step(dir, k-1), true
+	}
+	if s < 0 {
+		return geom.Vec{}, false
+	}
+	return geom.FromIndex(s, t.cols), true
+}
+
+// loops checks if the guard starting at pos heading into dir walks in a loop once obstacle is added.
+// seen must have 4 entries per cell, entries equal to gen mark turns taken during this check,
+// so the slice can be reused between checks without clearing it.
+func (t *jumpTable) loops(pos geom.Vec, dir geom.Dir, obstacle geom.Vec, seen []uint32, gen uint32) bool {
+	for {
+		stop, ok := t.next(pos, dir, obstacle)
+		if !ok {
+			return false
+		}
+		key := stop.Index(t.cols)*len(geom.Dirs) + int(dir)
+		if seen[key] == gen {
+			return true
+		}
+		seen[key] = gen
+		pos, dir = stop, dir.Right()
+	}
+}
@@ -2,33 +2,55 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"runtime"
 	"slices"
-	"sync"
-	"sync/atomic"
 	"time"
+
+	"github.com/floj/aoc2024/lib/geom"
 )
 
+type options struct {
+	input string
+	rules MoveRules
+	// print a loop witness for every obstacle and optionally draw the loop
+	witness bool
+	render  bool
+}
+
 func main() {
-	if err := runA("input.txt"); err != nil {
+	opts := options{}
+	turn := ""
+	flag.StringVar(&opts.input, "input", "input.txt", "puzzle input file")
+	flag.StringVar(&turn, "turn", "right", "direction guards turn to when facing an obstacle, left or right")
+	flag.BoolVar(&opts.rules.TurnAround, "turn-around", false, "let guards turn around in dead ends")
+	flag.BoolVar(&opts.witness, "witness", false, "print position and loop length for every loop causing obstacle")
+	flag.BoolVar(&opts.render, "render", false, "draw the loop of every loop causing obstacle, implies -witness")
+	flag.BoolVar(&opts.rules.Wrap, "wrap", false, "let guards continue on the opposite side instead of leaving the area")
+	flag.Parse()
+	switch turn {
+	case "right":
+	case "left":
+		opts.rules.TurnLeft = true
+	default:
+		fmt.Fprintf(os.Stderr, "invalid turn direction %s\n", turn)
+		os.Exit(1)
+	}
+
+	if err := runA(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "puzzle errored: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := runB("input.txt"); err != nil {
+	if err := runB(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "puzzle errored: %v\n", err)
 		os.Exit(1)
 	}
 }
 
 const (
-	NORTH byte = '^'
-	EAST       = '>'
-	SOUTH      = 'v'
-	WEST       = '<'
-
 	MOVED        state = 0
 	LEFT_FIELD         = iota
 	ENTERED_LOOP       = iota
@@ -36,29 +58,51 @@ const (
 
 type state int
 
-var playerSym = bytes.Runes([]byte{NORTH, EAST, SOUTH, WEST})
-
-func turn(d byte) byte {
-	switch d {
-	case NORTH:
-		return EAST
-	case EAST:
-		return SOUTH
-	case SOUTH:
-		return WEST
-	case WEST:
-		return NORTH
-	default:
-		panic("unknown direction")
+var playerSym = bytes.Runes([]byte{
+	geom.North.Arrow(),
+	geom.East.Arrow(),
+	geom.South.Arrow(),
+	geom.West.Arrow(),
+})
+
+// MoveRules configure how guards react to obstacles and the border of the area.
+// The zero value are the rules of the puzzle.
+type MoveRules struct {
+	// turn left instead of right when facing an obstacle
+	TurnLeft bool
+	// turn around if the way after turning is blocked as well
+	TurnAround bool
+	// continue on the opposite side instead of leaving the area
+	Wrap bool
+}
+
+func (r MoveRules) turn(d geom.Dir) geom.Dir {
+	if r.TurnLeft {
+		return d.Left()
 	}
+	return d.Right()
+}
+
+type guard struct {
+	// position and heading, tracked so moving doesn't need to search the field
+	pos geom.Vec
+	dir geom.Dir
+	// number of turns since the last step forward
+	turns int
+	// directions the guard walked into each field with, one bit per direction
+	visits  []byte
+	left    bool
+	looping bool
 }
 
 type Area struct {
 	fields []byte
-	visits []byte
 	cols   int
 	rows   int
 	moves  int
+	rules  MoveRules
+	// all guards move in lockstep, they don't block each other
+	guards []guard
 }
 
 func (a *Area) Count(v byte) int {
@@ -72,91 +116,128 @@ func (a *Area) Count(v byte) int {
 }
 
 func (a *Area) Get(x, y int) byte {
-	idx := y*a.cols + x
-	if idx >= len(a.fields) {
+	if x < 0 || y < 0 || x >= a.cols || y >= a.rows {
 		return 0
 	}
-	return a.fields[idx]
+	return a.fields[y*a.cols+x]
 }
 
+// Player returns the position of the first guard and its symbol, or -1,-1,0 if the guard left the field.
 func (a *Area) Player() (int, int, byte) {
-	pos := bytes.IndexFunc(a.fields, func(r rune) bool {
-		return slices.Contains(playerSym, r)
-	})
-	if pos < 0 {
+	if len(a.guards) == 0 || a.guards[0].left {
 		return -1, -1, 0
 	}
+	g := a.guards[0]
+	return g.pos.X, g.pos.Y, g.dir.Arrow()
+}
 
-	return pos % a.cols, pos / a.cols, a.fields[pos]
+// findGuards searches the field for the guards, only needed once when creating the area.
+func (a *Area) findGuards() {
+	for idx, b := range a.fields {
+		if !slices.Contains(playerSym, rune(b)) {
+			continue
+		}
+		a.guards = append(a.guards, guard{
+			pos:    geom.FromIndex(idx, a.cols),
+			dir:    geom.MustParseArrow(b),
+			visits: make([]byte, len(a.fields)),
+		})
+	}
 }
 
+// Move moves all guards one step. It returns LEFT_FIELD once all guards left the area,
+// ENTERED_LOOP once all remaining guards walk in loops and MOVED otherwise.
 func (a *Area) Move() state {
 	a.moves++
-	oldX, oldY, direction := a.Player()
-	// fmt.Fprintf(os.Stderr, "%d (%d,%d)\n", a.moves, oldX, oldY)
-	newX, newY := oldX, oldY
-	a.Set(oldX, oldY, 'X')
-	switch direction {
-	case NORTH:
-		newY--
-	case EAST:
-		newX++
-	case SOUTH:
-		newY++
-	case WEST:
-		newX--
-	default:
-		panic("unknown direction")
+	active, looping := 0, 0
+	for i := range a.guards {
+		g := &a.guards[i]
+		if g.left {
+			continue
+		}
+		switch a.moveGuard(g) {
+		case LEFT_FIELD:
+			g.left = true
+			continue
+		case ENTERED_LOOP:
+			g.looping = true
+		}
+		active++
+		if g.looping {
+			looping++
+		}
 	}
-	if newX < 0 || newX >= a.cols || newY < 0 || newY >= a.rows {
+	if active == 0 {
+		return LEFT_FIELD
+	}
+	if looping == active {
+		return ENTERED_LOOP
+	}
+	return MOVED
+}
+
+func (a *Area) moveGuard(g *guard) state {
+	// fmt.Fprintf(os.Stderr, "%d %s\n", a.moves, g.pos)
+	a.Set(g.pos.X, g.pos.Y, 'X')
+	next, ok := a.step(g.pos, g.dir)
+	if !ok {
+		g.pos = next
 		return LEFT_FIELD
 	}
 
-	// obstacle on new field, rotate right
-	if a.Get(newX, newY) == '#' {
-		direction = turn(direction)
-		a.Set(oldX, oldY, direction)
+	// obstacle on new field, turn
+	if a.Get(next.X, next.Y) == '#' {
+		dir := a.rules.turn(g.dir)
+		if a.rules.TurnAround {
+			if n, ok := a.step(g.pos, dir); ok && a.Get(n.X, n.Y) == '#' {
+				// dead end
+				dir = g.dir.Reverse()
+			}
+		}
+		g.dir = dir
+		a.Set(g.pos.X, g.pos.Y, g.dir.Arrow())
+		g.turns++
+		if g.turns == len(geom.Dirs) {
+			// surrounded by obstacles, the guard would turn forever
+			return ENTERED_LOOP
+		}
 		return MOVED
 	}
 
-	a.Set(newX, newY, direction)
-	if a.Visited(newX, newY, direction) {
+	g.turns = 0
+	g.pos = next
+	a.Set(next.X, next.Y, g.dir.Arrow())
+	if g.Visited(next.Index(a.cols), g.dir) {
 		return ENTERED_LOOP
 	}
 
 	return MOVED
 }
 
-func (a *Area) Set(x, y int, v byte) {
-	idx := y*a.cols + x
-	if idx >= len(a.fields) {
-		return
+// step returns the field next to pos in direction d and false if that's outside of the area.
+func (a *Area) step(pos geom.Vec, d geom.Dir) (geom.Vec, bool) {
+	next := pos.Add(d.Vec())
+	if a.rules.Wrap {
+		next.X = (next.X + a.cols) % a.cols
+		next.Y = (next.Y + a.rows) % a.rows
 	}
-	a.fields[idx] = v
+	return next, next.In(a.cols, a.rows)
 }
 
-func (a *Area) Visited(x, y int, direction byte) bool {
-	idx := y*a.cols + x
-	if idx >= len(a.fields) {
-		return true
+func (a *Area) Set(x, y int, v byte) {
+	if x < 0 || y < 0 || x >= a.cols || y >= a.rows {
+		return
 	}
+	a.fields[y*a.cols+x] = v
+}
 
-	flags := a.visits[idx]
-	switch direction {
-	case NORTH:
-		flags = flags | 0b0001
-	case EAST:
-		flags = flags | 0b0010
-	case SOUTH:
-		flags = flags | 0b0100
-	case WEST:
-		flags = flags | 0b1000
-	}
-	if a.visits[idx] == flags {
+func (g *guard) Visited(idx int, direction geom.Dir) bool {
+	flags := g.visits[idx] | direction.Bit()
+	if g.visits[idx] == flags {
 		// same field visited in the same direction -> loop
 		return true
 	}
-	a.visits[idx] = flags
+	g.visits[idx] = flags
 	return false
 }
 
@@ -173,80 +254,97 @@ func (a *Area) String() string {
 	return buf.String()
 }
 
+// Clone returns a deep copy of the area.
+func (a *Area) Clone() Area {
+	c := *a
+	c.fields = slices.Clone(a.fields)
+	c.guards = slices.Clone(a.guards)
+	for i := range c.guards {
+		c.guards[i].visits = slices.Clone(a.guards[i].visits)
+	}
+	return c
+}
+
+// Reset restores the area to the state of base, reusing the memory of the area.
+// Both areas must be clones of each other.
+func (a *Area) Reset(base *Area) {
+	copy(a.fields, base.fields)
+	a.moves = base.moves
+	a.rules = base.rules
+	for i := range a.guards {
+		visits := a.guards[i].visits
+		copy(visits, base.guards[i].visits)
+		a.guards[i] = base.guards[i]
+		a.guards[i].visits = visits
+	}
+}
+
 func NewArea(in []byte) Area {
+	return NewAreaWithRules(in, MoveRules{})
+}
+
+func NewAreaWithRules(in []byte, rules MoveRules) Area {
 	field := slices.Clone(in)
 	cols := bytes.Index(field, []byte{'\n'})
 	f := bytes.ReplaceAll(field, []byte{'\n'}, []byte{})
 	rows := len(f) / cols
 
-	return Area{
+	a := Area{
 		fields: f,
 		cols:   cols,
 		rows:   rows,
-		visits: make([]byte, len(f)),
+		rules:  rules,
 	}
+	a.findGuards()
+	return a
 }
 
-func runA(inputFile string) error {
-	in, err := os.ReadFile(inputFile)
+func runA(opts options) error {
+	in, err := os.ReadFile(opts.input)
 	if err != nil {
 		return err
 	}
 
-	a := NewArea(in)
-	for a.Move() == MOVED {
+	a := NewAreaWithRules(in, opts.rules)
+	s := MOVED
+	for s == MOVED {
+		s = a.Move()
 	}
 
+	if s == ENTERED_LOOP {
+		fmt.Printf("done, guards loop, visited %d\n", a.Count('X'))
+		return nil
+	}
 	fmt.Printf("done, visited %d\n", a.Count('X'))
 	return nil
 }
 
-func runB(inputFile string) error {
-	in, err := os.ReadFile(inputFile)
+func runB(opts options) error {
+	in, err := os.ReadFile(opts.input)
 	if err != nil {
 		return err
 	}
 
-	leftField, enteredLoop := &atomic.Int32{}, &atomic.Int32{}
-	field := NewArea(in).fields
-	remaining := &atomic.Int32{}
-	wg := &sync.WaitGroup{}
-
 	start := time.Now()
-	for range runtime.NumCPU() * 2 {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for {
-				i := remaining.Add(1)
-				if int(i) >= len(field) {
-					break
-				}
-				// if field is already taken (existing obstacle or player), skip
-				if field[i] != '.' {
-					continue
-				}
-				// add additional obstacle
-				a := NewArea(in)
-				a.fields[i] = '#'
-
-				s := MOVED
-				for s == MOVED {
-					s = a.Move()
-					switch s {
-					case LEFT_FIELD:
-						fmt.Fprintf(os.Stderr, "obstacle at %d of %d: OK\n", i, len(field))
-						leftField.Add(1)
-					case ENTERED_LOOP:
-						fmt.Fprintf(os.Stderr, "obstacle at %d of %d: LOOP\n", i, len(field))
-						enteredLoop.Add(1)
-					}
-				}
-			}
-		}()
-	}
+	a := NewAreaWithRules(in, opts.rules)
+	loops := findLoopObstacles(a)
+	fmt.Printf("done, looped %d, %v\n", len(loops), time.Since(start))
 
-	wg.Wait()
-	fmt.Printf("done, looped %d, %v\n", enteredLoop.Load(), time.Since(start))
+	if !opts.witness && !opts.render {
+		return nil
+	}
+	for _, idx := range loops {
+		w, ok := findLoopWitness(a, idx)
+		if !ok {
+			return fmt.Errorf("no loop found for obstacle at %s", geom.FromIndex(idx, a.cols))
+		}
+		fmt.Println(w)
+		if opts.render {
+			fmt.Println(w.Render())
+			fmt.Println()
+		}
+	}
 	return nil
 }
+
+var debugOut io.Writer = os.Stderr
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/floj/aoc2024/lib/geom"
+)
+
+// loopArea creates an empty area with four obstacles that keep the guard
+// walking along the border forever.
+func loopArea(cols, rows int) Area {
+	field := bytes.Repeat([]byte{'.'}, cols*rows)
+	field[1] = '#'                    // top
+	field[cols+cols-1] = '#'          // right
+	field[(rows-1)*cols+cols-2] = '#' // bottom
+	field[(rows-2)*cols] = '#'        // left
+	field[(rows-2)*cols+1] = '^'
+	in := bytes.Buffer{}
+	for i := 0; i < len(field); i += cols {
+		in.Write(field[i : i+cols])
+		in.WriteByte('\n')
+	}
+	return NewArea(in.Bytes())
+}
+
+func BenchmarkMove(b *testing.B) {
+	for _, size := range []int{16, 256, 2048} {
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			a := loopArea(size, size)
+			b.ResetTimer()
+			for range b.N {
+				if s := a.Move(); s == LEFT_FIELD {
+					b.Fatalf("guard left the field at %s", a.guards[0].pos)
+				}
+			}
+		})
+	}
+}
+
+// randomArea creates a map with random obstacles and the guard somewhere in it.
+func randomArea(rnd *rand.Rand, cols, rows int) []byte {
+	field := make([]byte, cols*rows)
+	for i := range field {
+		field[i] = '.'
+		if rnd.IntN(100) < 12 {
+			field[i] = '#'
+		}
+	}
+	field[rnd.IntN(len(field))] = geom.Dirs[rnd.IntN(len(geom.Dirs))].Arrow()
+	in := bytes.Buffer{}
+	for i := 0; i < len(field); i += cols {
+		in.Write(field[i : i+cols])
+		in.WriteByte('\n')
+	}
+	return in.Bytes()
+}
+
+// bruteForceLoops tries an obstacle on every free cell and walks the guard from the start.
+func bruteForceLoops(in []byte) []int {
+	loops := []int{}
+	base := NewArea(in)
+	for i, v := range base.fields {
+		if v != '.' {
+			continue
+		}
+		a := NewArea(in)
+		a.fields[i] = '#'
+		s := MOVED
+		for s == MOVED {
+			s = a.Move()
+		}
+		if s == ENTERED_LOOP {
+			loops = append(loops, i)
+		}
+	}
+	return loops
+}
+
+func TestFindLoopObstacles(t *testing.T) {
+	out := debugOut
+	debugOut = io.Discard
+	t.Cleanup(func() {
+		debugOut = out
+	})
+	rnd := rand.New(rand.NewPCG(6, 2024))
+	for i := range 200 {
+		in := randomArea(rnd, 5+rnd.IntN(20), 5+rnd.IntN(20))
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			expected := bruteForceLoops(in)
+			got := findLoopObstacles(NewArea(in))
+			if !slices.Equal(expected, got) {
+				t.Fatalf("expected loops at %v, got %v for\n%s", expected, got, in)
+			}
+		})
+	}
+}
+
+func TestMoveAfterLeaving(t *testing.T) {
+	a := NewArea([]byte("...\n..>\n...\n"))
+	if s := a.Move(); s != LEFT_FIELD {
+		t.Fatalf("expected guard to leave the field, got %d", s)
+	}
+	before := slices.Clone(a.fields)
+	if s := a.Move(); s != LEFT_FIELD {
+		t.Fatalf("expected guard to stay gone, got %d", s)
+	}
+	if !slices.Equal(before, a.fields) {
+		t.Fatalf("field changed after guard left:\n%s", a.String())
+	}
+}
+
+func TestMoveRules(t *testing.T) {
+	table := []struct {
+		name     string
+		in       string
+		rules    MoveRules
+		moves    int
+		expected state
+		pos      geom.Vec
+		dir      geom.Dir
+	}{
+		{name: "right", in: ".#.\n.^.\n...\n", moves: 1, expected: MOVED, pos: geom.Vec{X: 1, Y: 1}, dir: geom.East},
+		{name: "left", in: ".#.\n.^.\n...\n", rules: MoveRules{TurnLeft: true}, moves: 1, expected: MOVED, pos: geom.Vec{X: 1, Y: 1}, dir: geom.West},
+		{name: "dead end", in: ".#.\n.^#\n...\n", rules: MoveRules{TurnAround: true}, moves: 1, expected: MOVED, pos: geom.Vec{X: 1, Y: 1}, dir: geom.South},
+		{name: "no dead end", in: ".#.\n.^.\n...\n", rules: MoveRules{TurnAround: true}, moves: 1, expected: MOVED, pos: geom.Vec{X: 1, Y: 1}, dir: geom.East},
+		{name: "wrap", in: ".^.\n...\n...\n", rules: MoveRules{Wrap: true}, moves: 1, expected: MOVED, pos: geom.Vec{X: 1, Y: 2}, dir: geom.North},
+		{name: "wrap loops", in: ".^.\n...\n...\n", rules: MoveRules{Wrap: true}, moves: 4, expected: ENTERED_LOOP, pos: geom.Vec{X: 1, Y: 2}, dir: geom.North},
+	}
+	for _, td := range table {
+		t.Run(td.name, func(t *testing.T) {
+			a := NewAreaWithRules([]byte(td.in), td.rules)
+			s := MOVED
+			for range td.moves {
+				s = a.Move()
+			}
+			g := a.guards[0]
+			if s != td.expected || g.pos != td.pos || g.dir != td.dir {
+				t.Fatalf("expected %d at %s heading %s, got %d at %s heading %s", td.expected, td.pos, td.dir, s, g.pos, g.dir)
+			}
+		})
+	}
+}
+
+func TestMoveLockstep(t *testing.T) {
+	a := NewArea([]byte("^...\n...v\n....\n"))
+	if len(a.guards) != 2 {
+		t.Fatalf("expected 2 guards, got %d", len(a.guards))
+	}
+	if s := a.Move(); s != MOVED {
+		t.Fatalf("expected second guard to keep moving, got %d", s)
+	}
+	if !a.guards[0].left || a.guards[1].pos != (geom.Vec{X: 3, Y: 2}) {
+		t.Fatalf("guards did not move in lockstep: %+v %+v", a.guards[0].pos, a.guards[1].pos)
+	}
+	if s := a.Move(); s != LEFT_FIELD {
+		t.Fatalf("expected all guards to leave, got %d", s)
+	}
+}
+
+func TestFindLoopWitness(t *testing.T) {
+	in := []byte("....#.....\n" +
+		".........#\n" +
+		"..........\n" +
+		"..#.......\n" +
+		".......#..\n" +
+		"..........\n" +
+		".#..^.....\n" +
+		"........#.\n" +
+		"#.........\n" +
+		"......#...\n")
+	a := NewArea(in)
+
+	w, ok := findLoopWitness(a, geom.Vec{X: 3, Y: 6}.Index(a.cols))
+	if !ok {
+		t.Fatal("expected a loop")
+	}
+	if w.Obstacle != (geom.Vec{X: 3, Y: 6}) || w.Length != 18 {
+		t.Fatalf("expected obstacle at (3,6) with loop length 18, got %s", w)
+	}
+	expected := "....#.....\n" +
+		"....+---+#\n" +
+		"....|...|.\n" +
+		"..#.|...|.\n" +
+		"....|..#|.\n" +
+		"....|...|.\n" +
+		".#.O+---+.\n" +
+		"........#.\n" +
+		"#.........\n" +
+		"......#..."
+	if got := w.Render(); got != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	if _, ok := findLoopWitness(a, geom.Vec{X: 0, Y: 0}.Index(a.cols)); ok {
+		t.Fatal("expected no loop for obstacle at (0,0)")
+	}
+}
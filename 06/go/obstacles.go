@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/floj/aoc2024/lib/geom"
+)
+
+// obstacleCandidate is a cell the guard walks over without any additional obstacle,
+// together with the guard's state right before entering the cell for the first time.
+type obstacleCandidate struct {
+	idx int
+	pos geom.Vec
+	dir geom.Dir
+}
+
+// obstacleCandidates walks the guard through the unobstructed area. An obstacle anywhere else
+// would never be hit, so only the cells on this path can cause a loop.
+// If the guard already loops without an additional obstacle, every free cell is a candidate.
+func obstacleCandidates(base Area) []obstacleCandidate {
+	a := base.Clone()
+	g := &a.guards[0]
+	start := g.pos.Index(a.cols)
+	seen := make([]bool, len(a.fields))
+	seen[start] = true
+
+	candidates := []obstacleCandidate{}
+	for {
+		pos, dir := g.pos, g.dir
+		switch a.Move() {
+		case LEFT_FIELD:
+			return candidates
+		case ENTERED_LOOP:
+			return allFreeCells(base)
+		}
+		if g.pos == pos {
+			// turned
+			continue
+		}
+		idx := g.pos.Index(a.cols)
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		candidates = append(candidates, obstacleCandidate{idx: idx, pos: pos, dir: dir})
+	}
+}
+
+func allFreeCells(base Area) []obstacleCandidate {
+	candidates := []obstacleCandidate{}
+	for idx, v := range base.fields {
+		if v == '.' {
+			candidates = append(candidates, obstacleCandidate{idx: idx, pos: base.guards[0].pos, dir: base.guards[0].dir})
+		}
+	}
+	return candidates
+}
+
+// findLoopObstacles returns the field indices of all cells where an additional
+// obstacle makes the guards walk in a loop, sorted ascending.
+func findLoopObstacles(base Area) []int {
+	if len(base.guards) != 1 || base.rules != (MoveRules{}) {
+		return simulateLoopObstacles(base)
+	}
+
+	candidates := obstacleCandidates(base)
+
+	table := newJumpTable(&base)
+
+	loops := make([]bool, len(candidates))
+	next := &atomic.Int32{}
+	wg := &sync.WaitGroup{}
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// one buffer per worker, each check uses a new generation instead of clearing it
+			seen := make([]uint32, len(base.fields)*len(geom.Dirs))
+			gen := uint32(0)
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(candidates) {
+					break
+				}
+				c := candidates[i]
+				gen++
+
+				// start right in front of the new obstacle, the path up to here is not changed by it
+				obstacle := geom.FromIndex(c.idx, base.cols)
+				if table.loops(c.pos, c.dir, obstacle, seen, gen) {
+					fmt.Fprintf(debugOut, "obstacle at %s: LOOP\n", obstacle)
+					loops[i] = true
+				} else {
+					fmt.Fprintf(debugOut, "obstacle at %s: OK\n", obstacle)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	obstacles := []int{}
+	for i, c := range candidates {
+		if loops[i] {
+			obstacles = append(obstacles, c.idx)
+		}
+	}
+	slices.Sort(obstacles)
+	return obstacles
+}
+
+// simulateLoopObstacles tries an obstacle on every free cell and moves the guards from their start
+// until they leave or loop. Used for multiple guards or custom rules, where neither the guard's path
+// nor the jump table can be used to skip work.
+func simulateLoopObstacles(base Area) []int {
+	loops := make([]bool, len(base.fields))
+	next := &atomic.Int32{}
+	wg := &sync.WaitGroup{}
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// one buffer per worker, reset for every obstacle
+			a := base.Clone()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(base.fields) {
+					break
+				}
+				if base.fields[i] != '.' {
+					continue
+				}
+				a.Reset(&base)
+				a.fields[i] = '#'
+
+				s := MOVED
+				for s == MOVED {
+					s = a.Move()
+				}
+				if s == ENTERED_LOOP {
+					fmt.Fprintf(debugOut, "obstacle at %s: LOOP\n", geom.FromIndex(i, base.cols))
+					loops[i] = true
+				} else {
+					fmt.Fprintf(debugOut, "obstacle at %s: OK\n", geom.FromIndex(i, base.cols))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	obstacles := []int{}
+	for i, l := range loops {
+		if l {
+			obstacles = append(obstacles, i)
+		}
+	}
+	return obstacles
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/floj/aoc2024/lib/geom"
+)
+
+// loopWitness describes the loop a guard walks in after an obstacle was added.
+type loopWitness struct {
+	Obstacle geom.Vec
+	// number of steps forward the guard takes to walk the loop once, turns don't count
+	Length int
+	// the states the guard walks through on the loop, starting and ending in the same field
+	path []guardState
+	area *Area
+}
+
+type guardState struct {
+	pos geom.Vec
+	dir geom.Dir
+}
+
+// findLoopWitness adds an obstacle at idx and follows each guard until it repeats a state.
+// It returns false if no guard walks in a loop.
+func findLoopWitness(base Area, idx int) (loopWitness, bool) {
+	for i := range base.guards {
+		a := base.Clone()
+		a.fields[idx] = '#'
+		// follow a single guard, the others don't influence it
+		a.guards = a.guards[i : i+1]
+		g := &a.guards[0]
+
+		seen := map[guardState]int{}
+		states := []guardState{}
+		for {
+			s := guardState{pos: g.pos, dir: g.dir}
+			if start, ok := seen[s]; ok {
+				path := states[start:]
+				w := loopWitness{
+					Obstacle: geom.FromIndex(idx, a.cols),
+					path:     path,
+					area:     &base,
+				}
+				for j := range path {
+					if path[j].pos != path[(j+1)%len(path)].pos {
+						w.Length++
+					}
+				}
+				return w, true
+			}
+			seen[s] = len(states)
+			states = append(states, s)
+			if a.moveGuard(g) == LEFT_FIELD {
+				break
+			}
+		}
+	}
+	return loopWitness{}, false
+}
+
+func (w loopWitness) String() string {
+	return fmt.Sprintf("obstacle at %s, loop length %d", w.Obstacle, w.Length)
+}
+
+// Render draws the area with the loop path, using '|' and '-' for fields the guard walks
+// through vertically or horizontally, '+' for fields walked in both ways and 'O' for the obstacle.
+func (w loopWitness) Render() string {
+	const (
+		vertical   = 1
+		horizontal = 2
+	)
+	a := w.area
+	walked := make([]byte, len(a.fields))
+	for _, s := range w.path {
+		if s.dir.Horizontal() {
+			walked[s.pos.Index(a.cols)] |= horizontal
+		} else {
+			walked[s.pos.Index(a.cols)] |= vertical
+		}
+	}
+
+	buf := bytes.Buffer{}
+	for idx, b := range a.fields {
+		if idx > 0 && idx%a.cols == 0 {
+			buf.WriteByte('\n')
+		}
+		switch {
+		case idx == w.Obstacle.Index(a.cols):
+			b = 'O'
+		case walked[idx] == vertical:
+			b = '|'
+		case walked[idx] == horizontal:
+			b = '-'
+		case walked[idx] != 0:
+			b = '+'
+		}
+		buf.WriteByte(b)
+	}
+	return buf.String()
+}
@@ -0,0 +1,105 @@
+package main
+
+import "slices"
+
+// inverse undoes an operation: given the result and the right operand it returns
+// the left operand, or false if there is no left operand producing the result.
+// Like in the puzzle, all operands are expected to be non-negative.
+type inverse func(result, b int64) (int64, bool)
+
+func undoAdd(result, b int64) (int64, bool) {
+	return result - b, result-b >= 0
+}
+
+func undoMul(result, b int64) (int64, bool) {
+	if b == 0 || result%b != 0 {
+		return 0, false
+	}
+	return result / b, true
+}
+
+func undoConcat(result, b int64) (int64, bool) {
+	pow, ok := nextPow10(b)
+	if !ok || result%pow != b {
+		// result doesn't end with the digits of b
+		return 0, false
+	}
+	return result / pow, true
+}
+
+// solveBackward starts at the test value and undoes the operations from the right.
+// Operations that can't be undone prune the search, which makes it much faster
+// than trying all combinations from the left.
+func (c *calibration) solveBackward(set operatorSet, all bool) [][]int {
+	s := backwardSolver[int64]{
+		set:   set,
+		undo:  func(op operator, result, b int64) (int64, bool) { return op.undo(result, b) },
+		equal: func(a, b int64) bool { return a == b },
+		zero:  func(v int64) bool { return v == 0 },
+		ops:   make([]int, len(c.seq)-1),
+		all:   all,
+	}
+	s.solve(c.value, c.seq)
+	return s.found
+}
+
+type backwardSolver[T any] struct {
+	set   operatorSet
+	undo  func(op operator, result, b T) (T, bool)
+	equal func(a, b T) bool
+	zero  func(v T) bool
+	// operators of the current sequence, filled from the right
+	ops   []int
+	all   bool
+	found [][]int
+}
+
+// solve returns true once the search can stop.
+func (s *backwardSolver[T]) solve(result T, seq []T) bool {
+	last := len(seq) - 1
+	if last == 0 {
+		if !s.equal(result, seq[0]) {
+			return false
+		}
+		s.found = append(s.found, slices.Clone(s.ops))
+		return !s.all
+	}
+	for i, op := range s.set.ops {
+		if op.absorbsZero && s.zero(seq[last]) {
+			if !s.zero(result) {
+				continue
+			}
+			// any left operand works, so the remaining operators don't matter
+			s.ops[last-1] = i
+			if s.anyOps(last - 1) {
+				return true
+			}
+			continue
+		}
+		prev, ok := s.undo(op, result, seq[last])
+		if !ok {
+			continue
+		}
+		s.ops[last-1] = i
+		if s.solve(prev, seq[:last]) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyOps records all combinations of the first n operators as solutions,
+// it returns true once the search can stop.
+func (s *backwardSolver[T]) anyOps(n int) bool {
+	if n == 0 {
+		s.found = append(s.found, slices.Clone(s.ops))
+		return !s.all
+	}
+	for i := range s.set.ops {
+		s.ops[n-1] = i
+		if s.anyOps(n - 1) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package main
+
+import "math/big"
+
+// bigCalibration holds the numbers of a calibration as big integers.
+type bigCalibration struct {
+	value *big.Int
+	seq   []*big.Int
+}
+
+// toBig returns the calibration with big integers, converting the numbers if necessary.
+func (c *calibration) toBig() bigCalibration {
+	if c.big != nil {
+		return *c.big
+	}
+	b := bigCalibration{value: big.NewInt(c.value)}
+	for _, v := range c.seq {
+		b.seq = append(b.seq, big.NewInt(v))
+	}
+	return b
+}
+
+// Solve is like calibration.Solve, but calculates with big integers.
+func (b bigCalibration) Solve(set operatorSet, all bool) [][]int {
+	if len(b.seq) == 0 {
+		return nil
+	}
+	equal := func(x, y *big.Int) bool { return x.Cmp(y) == 0 }
+	if set.backward() {
+		s := backwardSolver[*big.Int]{
+			set:   set,
+			undo:  func(op operator, result, v *big.Int) (*big.Int, bool) { return op.undoBig(result, v) },
+			equal: equal,
+			zero:  func(v *big.Int) bool { return v.Sign() == 0 },
+			ops:   make([]int, len(b.seq)-1),
+			all:   all,
+		}
+		s.solve(b.value, b.seq)
+		return s.found
+	}
+	found, _ := solveForward(set, b.value, b.seq, all, set.EvalBig, equal)
+	return found
+}
+
+// EvalBig is like Eval, but calculates with big integers, which never overflow.
+func (s operatorSet) EvalBig(seq []*big.Int, ops []int) (*big.Int, error) {
+	v, _ := eval(s, seq, ops, func(op operator, a, b *big.Int) (*big.Int, bool) { return op.applyBig(a, b), true })
+	return v, nil
+}
+
+// The big integer operations always return a new value and never modify their arguments,
+// as those are shared between the different operator sequences.
+
+func bigAdd(a, b *big.Int) *big.Int {
+	return new(big.Int).Add(a, b)
+}
+
+func bigMul(a, b *big.Int) *big.Int {
+	return new(big.Int).Mul(a, b)
+}
+
+func bigConcat(a, b *big.Int) *big.Int {
+	v := new(big.Int).Mul(a, bigNextPow10(b))
+	return v.Add(v, b)
+}
+
+func undoBigAdd(result, b *big.Int) (*big.Int, bool) {
+	v := new(big.Int).Sub(result, b)
+	return v, v.Sign() >= 0
+}
+
+func undoBigMul(result, b *big.Int) (*big.Int, bool) {
+	if b.Sign() == 0 {
+		return nil, false
+	}
+	q, r := new(big.Int).QuoRem(result, b, new(big.Int))
+	return q, r.Sign() == 0
+}
+
+func undoBigConcat(result, b *big.Int) (*big.Int, bool) {
+	q, r := new(big.Int).QuoRem(result, bigNextPow10(b), new(big.Int))
+	// result has to end with the digits of b
+	return q, r.Cmp(b) == 0
+}
+
+var ten = big.NewInt(10)
+
+// bigNextPow10 returns the smallest power of 10 greater than v.
+func bigNextPow10(v *big.Int) *big.Int {
+	digits := len(v.Text(10))
+	if v.Sign() < 0 {
+		digits--
+	}
+	return new(big.Int).Exp(ten, big.NewInt(int64(digits)), nil)
+}
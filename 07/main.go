@@ -1,47 +1,61 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"math/big"
 	"os"
-	"strconv"
+	"runtime"
+	"slices"
 	"strings"
 )
 
-type operation func(a, b int64) int64
-
-var opsPartA = map[byte]operation{
-	'+': func(a, b int64) int64 { return a + b },
-	'*': func(a, b int64) int64 { return a * b },
-}
-
-var opsPartB = map[byte]operation{
-	'+': func(a, b int64) int64 { return a + b },
-	'*': func(a, b int64) int64 { return a * b },
-	'|': func(a, b int64) int64 {
-		i := strconv.FormatInt(a, 10) + strconv.FormatInt(b, 10)
-		v, err := strconv.ParseInt(i, 10, 64)
-		if err != nil {
-			panic(err)
-		}
-		return v
-	},
-}
-
 type calibration struct {
 	value int64
 	seq   []int64
+	// set instead of value and seq if any number doesn't fit into an int64
+	big *bigCalibration
+}
+
+type options struct {
+	input string
+	// print the operators of every valid calibration
+	explain bool
+	// print all operator sequences instead of only the first one
+	all bool
+	// evaluate with standard precedence instead of left to right
+	precedence bool
+	// number of calibrations solved in parallel
+	workers int
+	// report calibrations taking longer than this factor times the median, 0 to disable
+	outliers float64
 }
 
 func main() {
+	opts := options{}
+	flag.StringVar(&opts.input, "input", "input.txt", "puzzle input file")
+	flag.BoolVar(&opts.explain, "explain", false, "print the operators making each calibration valid")
+	flag.BoolVar(&opts.all, "all", false, "print all operator sequences and their count, implies -explain")
+	flag.BoolVar(&opts.precedence, "precedence", false, "evaluate || before * before + instead of strictly left to right")
+	flag.IntVar(&opts.workers, "workers", runtime.NumCPU(), "number of calibrations solved in parallel")
+	flag.Float64Var(&opts.outliers, "outliers", 0, "report calibrations taking longer than this factor times the median")
+	flag.Parse()
+
+	setA, setB := partA, partB
+	if opts.precedence {
+		setA.order = standardPrecedence
+		setB.order = standardPrecedence
+	}
 
-	sumA, err := run("input.txt", opsPartA)
+	sumA, err := run(opts, setA)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "puzzle errored: %v\n", err)
 		os.Exit(1)
 	}
 	fmt.Fprintf(os.Stdout, "A: sum of valid calibrations: %d\n", sumA)
 
-	sumB, err := run("input.txt", opsPartB)
+	sumB, err := run(opts, setB)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "puzzle errored: %v\n", err)
 		os.Exit(1)
@@ -56,68 +70,150 @@ func newCalibration(line string) (calibration, error) {
 	if !found {
 		return c, fmt.Errorf("no ':' found in line: %s", line)
 	}
-	value, err := strconv.Atoi(v)
-	if err != nil {
-		return c, fmt.Errorf("invalid test value %s: %w", v, err)
+	value, ok := new(big.Int).SetString(v, 10)
+	if !ok || value.Sign() < 0 {
+		return c, fmt.Errorf("invalid test value %s", v)
 	}
-	c.value = int64(value)
+	b := bigCalibration{value: value}
+	fits := value.IsInt64()
 	s = strings.TrimSpace(s)
 	for _, i := range strings.Split(s, " ") {
-		v, err := strconv.Atoi(i)
-		if err != nil {
-			return c, fmt.Errorf("invalid sequence value %s in %s: %w", i, s, err)
+		v, ok := new(big.Int).SetString(i, 10)
+		if !ok || v.Sign() < 0 {
+			return c, fmt.Errorf("invalid sequence value %s in %s", i, s)
 		}
-		c.seq = append(c.seq, int64(v))
+		b.seq = append(b.seq, v)
+		fits = fits && v.IsInt64()
+	}
+
+	if !fits {
+		c.big = &b
+		return c, nil
+	}
+	c.value = b.value.Int64()
+	for _, v := range b.seq {
+		c.seq = append(c.seq, v.Int64())
 	}
 	return c, nil
 }
 
-func (c *calibration) IsValid(ops map[byte]operation) bool {
+// Solve returns the operator sequences making the calibration valid, each one listing
+// the indices of the operators in the set from left to right. It stops after the first
+// sequence unless all is set. If any number doesn't fit into an int64, or any intermediate
+// result would overflow, the calibration is solved with big integers instead.
+func (c *calibration) Solve(set operatorSet, all bool) [][]int {
+	if c.big != nil {
+		return c.big.Solve(set, all)
+	}
 	if len(c.seq) == 0 {
-		return false
+		return nil
 	}
+	if set.backward() {
+		// values only get smaller when solving backwards, no need to check for overflows
+		return c.solveBackward(set, all)
+	}
+	found, err := c.solveForward(set, all)
+	if errors.Is(err, ErrOverflow) {
+		return c.toBig().Solve(set, all)
+	}
+	return found
+}
+
+// solveForward tries all combinations of operators, this works for any operator set.
+// Overflows don't stop the search, but are reported with ErrOverflow as the calibration
+// might be valid beyond the range of int64.
+func (c *calibration) solveForward(set operatorSet, all bool) ([][]int, error) {
+	equal := func(a, b int64) bool { return a == b }
+	return solveForward(set, c.value, c.seq, all, set.Eval, equal)
+}
 
-	s := c.seq[0]
-	for k := range ops {
-		if solve(s, c.seq[1:], k, ops, c.value) {
-			return true
+func solveForward[T any](set operatorSet, value T, seq []T, all bool, eval func(seq []T, ops []int) (T, error), equal func(a, b T) bool) ([][]int, error) {
+	found := [][]int{}
+	var overflow error
+	ops := make([]int, len(seq)-1)
+	var solve func(i int) bool
+	solve = func(i int) bool {
+		if i == len(ops) {
+			v, err := eval(seq, ops)
+			if err != nil {
+				overflow = err
+				return false
+			}
+			if !equal(v, value) {
+				return false
+			}
+			found = append(found, slices.Clone(ops))
+			return !all
 		}
+		for o := range set.ops {
+			ops[i] = o
+			if solve(i + 1) {
+				return true
+			}
+		}
+		return false
 	}
-	return false
+	solve(0)
+	return found, overflow
 }
 
-func solve(sum int64, rest []int64, op byte, ops map[byte]operation, expected int64) bool {
-	if len(rest) == 0 {
-		return expected == sum
+// Value returns the test value of the calibration.
+func (c *calibration) Value() *big.Int {
+	if c.big != nil {
+		return c.big.value
 	}
+	return big.NewInt(c.value)
+}
 
-	s := ops[op](sum, rest[0])
-
-	for k := range ops {
-		if solve(s, rest[1:], k, ops, expected) {
-			return true
-		}
+// Format prints the calibration with the given operators, e.g. "3267: 81 + 40 * 27".
+func (c *calibration) Format(set operatorSet, ops []int) string {
+	b := c.toBig()
+	buf := strings.Builder{}
+	fmt.Fprintf(&buf, "%d: %d", b.value, b.seq[0])
+	for i, v := range b.seq[1:] {
+		fmt.Fprintf(&buf, " %s %d", set.ops[ops[i]].symbol, v)
 	}
-
-	return false
+	return buf.String()
 }
 
-func run(inputFile string, ops map[byte]operation) (int64, error) {
-	in, err := os.ReadFile(inputFile)
+func run(opts options, set operatorSet) (*big.Int, error) {
+	in, err := os.ReadFile(opts.input)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
 
-	sum := int64(0)
-	for _, line := range strings.Split(string(in), "\n") {
-		c, err := newCalibration(line)
+	lines := strings.Split(string(in), "\n")
+	cs := make([]calibration, len(lines))
+	for i, line := range lines {
+		cs[i], err = newCalibration(line)
 		if err != nil {
-			return -1, err
+			return nil, err
+		}
+	}
+
+	results := solveAll(cs, set, opts.all, opts.workers)
+
+	// sum up in input order, so the output doesn't depend on the workers
+	sum := new(big.Int)
+	for i, r := range results {
+		if len(r.solutions) == 0 {
+			continue
 		}
-		if c.IsValid(ops) {
-			sum += c.value
+		c := cs[i]
+		sum.Add(sum, c.Value())
+		if opts.all {
+			fmt.Printf("%d: %d operator sequences\n", c.Value(), len(r.solutions))
+			for _, ops := range r.solutions {
+				fmt.Printf("  %s\n", c.Format(set, ops))
+			}
+		} else if opts.explain {
+			fmt.Println(c.Format(set, r.solutions[0]))
 		}
 	}
 
+	if opts.outliers > 0 {
+		printOutliers(os.Stdout, lines, results, opts.outliers)
+	}
+
 	return sum, nil
 }
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// randomCalibration creates a calibration with n non-negative numbers. About half of them are
+// solvable by construction, the test value of the others is random, as are the test values
+// which would overflow.
+func randomCalibration(rnd *rand.Rand, n int, set operatorSet) calibration {
+	c := calibration{}
+	for range n {
+		c.seq = append(c.seq, int64(rnd.IntN(100)))
+	}
+	if rnd.IntN(2) == 0 {
+		c.value = int64(1 + rnd.IntN(1_000_000))
+		return c
+	}
+	ops := make([]int, n-1)
+	for i := range ops {
+		ops[i] = rnd.IntN(len(set.ops))
+	}
+	v, err := set.Eval(c.seq, ops)
+	if err != nil {
+		v = int64(1 + rnd.IntN(1_000_000))
+	}
+	c.value = v
+	return c
+}
+
+func TestBackwardMatchesForward(t *testing.T) {
+	table := []struct {
+		name string
+		set  operatorSet
+	}{
+		{name: "A", set: partA},
+		{name: "B", set: partB},
+	}
+	rnd := rand.New(rand.NewPCG(7, 2024))
+	for _, td := range table {
+		t.Run(td.name, func(t *testing.T) {
+			for range 2000 {
+				c := randomCalibration(rnd, 1+rnd.IntN(6), td.set)
+				expected, err := c.solveForward(td.set, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := c.solveBackward(td.set, true)
+				slices.SortFunc(expected, slices.Compare)
+				slices.SortFunc(got, slices.Compare)
+				if !slices.EqualFunc(expected, got, slices.Equal) {
+					t.Fatalf("expected %v, got %v for %d: %v", expected, got, c.value, c.seq)
+				}
+			}
+		})
+	}
+}
+
+func TestSolveExample(t *testing.T) {
+	table := []struct {
+		line string
+		a, b bool
+	}{
+		{line: "190: 10 19", a: true, b: true},
+		{line: "3267: 81 40 27", a: true, b: true},
+		{line: "83: 17 5", a: false, b: false},
+		{line: "156: 15 6", a: false, b: true},
+		{line: "7290: 6 8 6 15", a: false, b: true},
+		{line: "161011: 16 10 13", a: false, b: false},
+		{line: "192: 17 8 14", a: false, b: true},
+		{line: "21037: 9 7 18 13", a: false, b: false},
+		{line: "292: 11 6 16 20", a: true, b: true},
+	}
+	for _, td := range table {
+		t.Run(td.line, func(t *testing.T) {
+			c, err := newCalibration(td.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Solve(partA, false); (len(got) > 0) != td.a {
+				t.Errorf("part A: expected %v, got %v", td.a, got)
+			}
+			if got := c.Solve(partB, false); (len(got) > 0) != td.b {
+				t.Errorf("part B: expected %v, got %v", td.b, got)
+			}
+		})
+	}
+}
+
+func TestSolve(t *testing.T) {
+	precedence := operatorSet{ops: partB.ops, order: standardPrecedence}
+	table := []struct {
+		line     string
+		set      operatorSet
+		expected []string
+	}{
+		{line: "190: 10 19", set: partA, expected: []string{"190: 10 * 19"}},
+		{line: "3267: 81 40 27", set: partA, expected: []string{"3267: 81 * 40 + 27", "3267: 81 + 40 * 27"}},
+		{line: "83: 17 5", set: partB, expected: nil},
+		{line: "7290: 6 8 6 15", set: partB, expected: []string{"7290: 6 * 8 || 6 * 15"}},
+		{line: "4: 2 2", set: partB, expected: []string{"4: 2 + 2", "4: 2 * 2"}},
+		{line: "0: 5 0", set: partA, expected: []string{"0: 5 * 0"}},
+		{line: "0: 5 0", set: precedence, expected: []string{"0: 5 * 0"}},
+		{line: "50: 5 0", set: partB, expected: []string{"50: 5 || 0"}},
+		{line: "3267: 81 40 27", set: precedence, expected: []string{"3267: 81 * 40 + 27"}},
+		{line: "1161: 81 40 27", set: precedence, expected: []string{"1161: 81 + 40 * 27"}},
+		{line: "68: 2 3 4", set: precedence, expected: []string{"68: 2 * 3 || 4"}},
+		// 2 * 3 * 4611686018427387903 overflows
+		{line: "4611686018427387909: 2 3 4611686018427387903", set: precedence, expected: []string{"4611686018427387909: 2 * 3 + 4611686018427387903"}},
+		{line: "123456789012345678901234567890: 123456789012345 678901234567890", set: partB, expected: []string{"123456789012345678901234567890: 123456789012345 || 678901234567890"}},
+		{line: "1000000000000000000000000000000: 1000000000000000 1000000000000000", set: partA, expected: []string{"1000000000000000000000000000000: 1000000000000000 * 1000000000000000"}},
+		{line: "1000000000000000000000000000000: 1000000000000000 1000000000000000", set: precedence, expected: []string{"1000000000000000000000000000000: 1000000000000000 * 1000000000000000"}},
+		{line: "100000000000000000001: 100000000000000000000 1", set: partA, expected: []string{"100000000000000000001: 100000000000000000000 + 1"}},
+	}
+	for _, td := range table {
+		t.Run(td.line, func(t *testing.T) {
+			c, err := newCalibration(td.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			all := c.Solve(td.set, true)
+			got := []string{}
+			for _, ops := range all {
+				got = append(got, c.Format(td.set, ops))
+			}
+			if !slices.Equal(td.expected, got) {
+				t.Fatalf("expected %q, got %q", td.expected, got)
+			}
+			first := c.Solve(td.set, false)
+			if len(td.expected) > 0 && (len(first) != 1 || c.Format(td.set, first[0]) != td.expected[0]) {
+				t.Fatalf("expected only %q when stopping at the first solution, got %d solutions", td.expected[0], len(first))
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	precedence := operatorSet{ops: partB.ops, order: standardPrecedence}
+	table := []struct {
+		seq      []int64
+		ops      []int
+		set      operatorSet
+		expected int64
+		err      error
+	}{
+		{seq: []int64{2, 3, 4}, ops: []int{0, 1}, set: partB, expected: 20},
+		{seq: []int64{2, 3, 4}, ops: []int{0, 1}, set: precedence, expected: 14},
+		{seq: []int64{2, 3, 4}, ops: []int{1, 2}, set: partB, expected: 64},
+		{seq: []int64{2, 3, 4}, ops: []int{1, 2}, set: precedence, expected: 68},
+		{seq: []int64{2, 3, 4, 5}, ops: []int{1, 0, 1}, set: precedence, expected: 26},
+		{seq: []int64{math.MaxInt64, 1}, ops: []int{0}, set: partA, err: ErrOverflow},
+		{seq: []int64{math.MaxInt64 / 2, 3}, ops: []int{1}, set: partA, err: ErrOverflow},
+		{seq: []int64{math.MaxInt64 / 10, 99}, ops: []int{2}, set: partB, err: ErrOverflow},
+		{seq: []int64{1, 1_000_000_000_000_000_000}, ops: []int{2}, set: partB, err: ErrOverflow},
+		{seq: []int64{2, 2, math.MaxInt64 / 2}, ops: []int{0, 1}, set: precedence, err: ErrOverflow},
+	}
+	for _, td := range table {
+		t.Run(fmt.Sprint(td.seq, td.ops, td.set.order), func(t *testing.T) {
+			got, err := td.set.Eval(td.seq, td.ops)
+			if !errors.Is(err, td.err) {
+				t.Fatalf("expected error %v, got %v", td.err, err)
+			}
+			if got != td.expected {
+				t.Fatalf("expected %d, got %d", td.expected, got)
+			}
+		})
+	}
+}
+
+func TestBigMatchesInt(t *testing.T) {
+	precedence := operatorSet{ops: partB.ops, order: standardPrecedence}
+	rnd := rand.New(rand.NewPCG(7, 45))
+	for _, set := range []operatorSet{partA, partB, precedence} {
+		for range 500 {
+			c := randomCalibration(rnd, 1+rnd.IntN(6), set)
+			expected := c.Solve(set, true)
+			got := c.toBig().Solve(set, true)
+			if !slices.EqualFunc(expected, got, slices.Equal) {
+				t.Fatalf("expected %v, got %v for %d: %v", expected, got, c.value, c.seq)
+			}
+		}
+	}
+}
+
+func TestNewCalibration(t *testing.T) {
+	c, err := newCalibration("292: 11 6 16 20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.big != nil || c.value != 292 || !slices.Equal(c.seq, []int64{11, 6, 16, 20}) {
+		t.Fatalf("unexpected calibration %+v", c)
+	}
+
+	c, err = newCalibration("123456789012345678901234567890: 1 9223372036854775808")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.big == nil || c.Value().String() != "123456789012345678901234567890" || c.big.seq[1].String() != "9223372036854775808" {
+		t.Fatalf("expected big calibration, got %+v", c)
+	}
+
+	for _, line := range []string{"", "12 3", "x: 1 2", "12: 1 y", "-1: 1", "1: 2 -1"} {
+		if _, err := newCalibration(line); err == nil {
+			t.Errorf("expected error for %q", line)
+		}
+	}
+}
+
+func BenchmarkSolve(b *testing.B) {
+	rnd := rand.New(rand.NewPCG(7, 7))
+	for _, n := range []int{4, 8, 12} {
+		cs := make([]calibration, 64)
+		for i := range cs {
+			cs[i] = randomCalibration(rnd, n, partB)
+		}
+		b.Run(fmt.Sprintf("forward/%d", n), func(b *testing.B) {
+			for i := range b.N {
+				cs[i%len(cs)].solveForward(partB, false)
+			}
+		})
+		b.Run(fmt.Sprintf("backward/%d", n), func(b *testing.B) {
+			for i := range b.N {
+				cs[i%len(cs)].solveBackward(partB, false)
+			}
+		})
+	}
+}
+
+func TestSolveAllDeterministic(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(7, 46))
+	cs := make([]calibration, 300)
+	for i := range cs {
+		cs[i] = randomCalibration(rnd, 2+rnd.IntN(8), partB)
+	}
+	expected := solveAll(cs, partB, true, 1)
+	for _, workers := range []int{0, 2, 8, 64} {
+		got := solveAll(cs, partB, true, workers)
+		for i := range cs {
+			if !slices.EqualFunc(expected[i].solutions, got[i].solutions, slices.Equal) {
+				t.Fatalf("%d workers: expected %v, got %v for line %d", workers, expected[i].solutions, got[i].solutions, i)
+			}
+		}
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	results := []result{
+		{took: 10 * time.Millisecond},
+		{took: 900 * time.Millisecond},
+		{took: 12 * time.Millisecond},
+		{took: 11 * time.Millisecond},
+		{took: 200 * time.Millisecond},
+	}
+	if got := outliers(results, 10); !slices.Equal(got, []int{1, 4}) {
+		t.Fatalf("expected outliers [1 4], got %v", got)
+	}
+	if got := outliers(results, 100); len(got) != 0 {
+		t.Fatalf("expected no outliers, got %v", got)
+	}
+	if got := outliers(nil, 10); len(got) != 0 {
+		t.Fatalf("expected no outliers, got %v", got)
+	}
+}
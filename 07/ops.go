@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// ErrOverflow is reported when evaluating a calibration exceeds the range of int64.
+var ErrOverflow = errors.New("integer overflow")
+
+// operator is a binary operator usable in calibrations.
+type operator struct {
+	symbol string
+	// apply returns false if the result doesn't fit into an int64
+	apply func(a, b int64) (int64, bool)
+	// undo is the inverse of apply used to solve backwards, nil if the operator can't be undone
+	undo inverse
+	// applyBig and undoBig are the same as apply and undo for big integers, they never overflow
+	applyBig func(a, b *big.Int) *big.Int
+	undoBig  func(result, b *big.Int) (*big.Int, bool)
+	// operators with higher precedence are evaluated first, unless evaluating left to right
+	precedence int
+	// a right operand of 0 results in 0 for any left operand, so undo can't recover it
+	absorbsZero bool
+}
+
+type evalOrder int
+
+const (
+	// evaluate strictly from left to right, as in the puzzle
+	leftToRight evalOrder = iota
+	// evaluate operators with higher precedence first, operators with the same precedence left to right
+	standardPrecedence
+)
+
+// operatorSet lists the operators that may be placed between the numbers of a calibration.
+type operatorSet struct {
+	ops   []operator
+	order evalOrder
+}
+
+var (
+	addOp = operator{
+		symbol: "+", precedence: 1,
+		apply: checkedAdd, undo: undoAdd,
+		applyBig: bigAdd, undoBig: undoBigAdd,
+	}
+	mulOp = operator{
+		symbol: "*", precedence: 2,
+		apply: checkedMul, undo: undoMul,
+		applyBig: bigMul, undoBig: undoBigMul,
+		absorbsZero: true,
+	}
+	concatOp = operator{
+		symbol: "||", precedence: 3,
+		apply: checkedConcat, undo: undoConcat,
+		applyBig: bigConcat, undoBig: undoBigConcat,
+	}
+)
+
+var partA = operatorSet{ops: []operator{addOp, mulOp}}
+
+var partB = operatorSet{ops: []operator{addOp, mulOp, concatOp}}
+
+// backward reports whether calibrations can be solved backwards with the set,
+// which needs all operators to be undoable and left to right evaluation.
+func (s operatorSet) backward() bool {
+	if s.order != leftToRight {
+		return false
+	}
+	for _, op := range s.ops {
+		if op.undo == nil || op.undoBig == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Eval calculates the result of placing the operators with the given indices between the numbers of seq.
+func (s operatorSet) Eval(seq []int64, ops []int) (int64, error) {
+	v, ok := eval(s, seq, ops, func(op operator, a, b int64) (int64, bool) { return op.apply(a, b) })
+	if !ok {
+		return 0, ErrOverflow
+	}
+	return v, nil
+}
+
+// eval places the operators between the numbers of seq and combines them with apply,
+// it stops and returns false as soon as apply does.
+func eval[T any](s operatorSet, seq []T, ops []int, apply func(op operator, a, b T) (T, bool)) (T, bool) {
+	if s.order == leftToRight {
+		v := seq[0]
+		for i, o := range ops {
+			var ok bool
+			if v, ok = apply(s.ops[o], v, seq[i+1]); !ok {
+				return v, false
+			}
+		}
+		return v, true
+	}
+
+	// operands and operators waiting for an operator with lower precedence
+	vals := []T{seq[0]}
+	pending := []int{}
+	reduce := func() bool {
+		a, b := vals[len(vals)-2], vals[len(vals)-1]
+		v, ok := apply(s.ops[pending[len(pending)-1]], a, b)
+		vals = append(vals[:len(vals)-2], v)
+		pending = pending[:len(pending)-1]
+		return ok
+	}
+	for i, o := range ops {
+		for len(pending) > 0 && s.ops[pending[len(pending)-1]].precedence >= s.ops[o].precedence {
+			if !reduce() {
+				return vals[0], false
+			}
+		}
+		pending = append(pending, o)
+		vals = append(vals, seq[i+1])
+	}
+	for len(pending) > 0 {
+		if !reduce() {
+			return vals[0], false
+		}
+	}
+	return vals[0], true
+}
+
+func checkedAdd(a, b int64) (int64, bool) {
+	v := a + b
+	// overflow flips the sign compared to both operands
+	return v, (v > a) == (b > 0)
+}
+
+func checkedMul(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	v := a * b
+	return v, v/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+}
+
+func checkedConcat(a, b int64) (int64, bool) {
+	pow, ok := nextPow10(b)
+	if !ok {
+		return 0, false
+	}
+	v, ok := checkedMul(a, pow)
+	if !ok {
+		return 0, false
+	}
+	return checkedAdd(v, b)
+}
+
+// nextPow10 returns the smallest power of 10 greater than v, which is the factor
+// the left operand has to be shifted by to append the digits of v.
+// It returns false if that power doesn't fit into an int64.
+func nextPow10(v int64) (int64, bool) {
+	pow := int64(10)
+	for v >= pow {
+		if pow > math.MaxInt64/10 {
+			return 0, false
+		}
+		pow *= 10
+	}
+	return pow, true
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// result of solving a single calibration.
+type result struct {
+	solutions [][]int
+	took      time.Duration
+}
+
+// solveAll solves the calibrations using the given number of workers, at least one.
+// The results are in the same order as the calibrations, independent of the number of workers.
+func solveAll(cs []calibration, set operatorSet, all bool, workers int) []result {
+	results := make([]result, len(cs))
+	next := &atomic.Int32{}
+	wg := &sync.WaitGroup{}
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(cs) {
+					break
+				}
+				start := time.Now()
+				results[i].solutions = cs[i].Solve(set, all)
+				results[i].took = time.Since(start)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// outliers returns the indices of the results taking longer than factor times the median, slowest first.
+func outliers(results []result, factor float64) []int {
+	if len(results) == 0 {
+		return nil
+	}
+	took := make([]time.Duration, len(results))
+	for i, r := range results {
+		took[i] = r.took
+	}
+	slices.Sort(took)
+	limit := time.Duration(float64(took[len(took)/2]) * factor)
+
+	slow := []int{}
+	for i, r := range results {
+		if r.took > limit {
+			slow = append(slow, i)
+		}
+	}
+	slices.SortStableFunc(slow, func(a, b int) int {
+		return cmp.Compare(results[b].took, results[a].took)
+	})
+	return slow
+}
+
+func printOutliers(w io.Writer, lines []string, results []result, factor float64) {
+	slow := outliers(results, factor)
+	if len(slow) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%d lines took more than %.1f times the median:\n", len(slow), factor)
+	for _, i := range slow {
+		fmt.Fprintf(w, "  line %d: %v, %s\n", i+1, results[i].took, lines[i])
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"maps"
+	"slices"
+)
+
+// antinodeModel configures where two antennas of the same frequency create antinodes.
+type antinodeModel struct {
+	// range of multiples of the distance between two antennas, counted outwards from each antenna:
+	// 0 is the antenna itself, 1 the point twice as far away from the other antenna as this one.
+	// A negative max continues until leaving the map.
+	min, max int
+	// step along the line in the distance divided by the GCD of its components, so all grid
+	// points on the line are antinodes, including the ones between the two antennas
+	lattice bool
+}
+
+var (
+	// antinodes only at twice the distance
+	partA = antinodeModel{min: 1, max: 1}
+	// antinodes everywhere in line with the antennas, in multiples of their distance
+	partB = antinodeModel{min: 0, max: -1}
+)
+
+// coordSet is a set of positions, used to collect antinodes without touching the grid.
+type coordSet map[coord]struct{}
+
+func (s coordSet) Add(c coord) {
+	s[c] = struct{}{}
+}
+
+// Sorted returns the positions ordered by row, then column.
+func (s coordSet) Sorted() []coord {
+	return slices.SortedFunc(maps.Keys(s), func(a, b coord) int {
+		if a.y != b.y {
+			return a.y - b.y
+		}
+		return a.x - b.x
+	})
+}
+
+// bounds is the size of a map, positions outside can't hold antinodes.
+type bounds struct {
+	cols, rows int
+}
+
+func (b bounds) In(c coord) bool {
+	return c.x >= 0 && c.y >= 0 && c.x < b.cols && c.y < b.rows
+}
+
+// Antinodes returns all antinodes created by pairs of the given antennas, which share a frequency.
+func (m antinodeModel) Antinodes(antennas []coord, b bounds) coordSet {
+	found := coordSet{}
+	for _, p := range permute(antennas) {
+		m.pairAntinodes(p, b, found.Add)
+	}
+	return found
+}
+
+func (m antinodeModel) pairAntinodes(p coordPair, b bounds, emit func(coord)) {
+	dist := p.Distance()
+	if dist == (coord{}) {
+		// same position, there is no line through them
+		return
+	}
+	step, n := dist, 1
+	if m.lattice {
+		n = gcd(abs(dist.x), abs(dist.y))
+		step = coord{x: dist.x / n, y: dist.y / n}
+	}
+
+	// p.c1 is dist away from p.c2, so walking dist from p.c1 goes away from p.c2
+	for _, start := range []struct {
+		pos  coord
+		step coord
+	}{{p.c1, step}, {p.c2, step.Invert()}} {
+		for i := m.min * n; m.max < 0 || i <= m.max*n; i++ {
+			pos := start.pos.Add(start.step.Mul(i))
+			if !b.In(pos) {
+				break
+			}
+			emit(pos)
+		}
+	}
+
+	if m.lattice {
+		// the grid points between both antennas
+		for i := 1; i < n; i++ {
+			if pos := p.c2.Add(step.Mul(i)); b.In(pos) {
+				emit(pos)
+			}
+		}
+	}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,3 @@
+module github.com/floj/aoc2024/08
+
+go 1.23.4
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"os"
 	"slices"
@@ -14,16 +15,26 @@ type Grid struct {
 	cols  int
 }
 
-func NewGrid(in []byte) *Grid {
-	cols := bytes.IndexByte(in, '\n')
-	if cols < 0 {
-		panic("no line breal in input")
+// NewGrid parses a map with one row per line. Rows shorter than the longest one
+// are filled up with empty cells.
+func NewGrid(in []byte) (*Grid, error) {
+	lines := bytes.Split(bytes.TrimRight(in, "\n"), []byte{'\n'})
+	cols := 0
+	for _, l := range lines {
+		cols = max(cols, len(l))
+	}
+	if cols == 0 {
+		return nil, fmt.Errorf("empty map")
 	}
 	g := Grid{
-		field: bytes.ReplaceAll(in, []byte{'\n'}, []byte{}),
+		field: make([]byte, 0, cols*len(lines)),
 		cols:  cols,
 	}
-	return &g
+	for _, l := range lines {
+		g.field = append(g.field, l...)
+		g.field = append(g.field, bytes.Repeat([]byte{'.'}, cols-len(l))...)
+	}
+	return &g, nil
 }
 
 func (g *Grid) Clone() *Grid {
@@ -35,10 +46,19 @@ func (g *Grid) Clone() *Grid {
 
 func (g *Grid) String() string {
 	b := bytes.Buffer{}
-	numLen := len(strconv.Itoa(max(g.cols, len(g.field)/g.cols)))
+	writeFrame(&b, g.cols, len(g.field)/g.cols, func(b *bytes.Buffer, y int) {
+		//b.Write(bytes.ReplaceAll(g.field[i:i+g.cols], []byte{'.'}, []byte{' '}))
+		b.Write(g.field[y*g.cols : (y+1)*g.cols])
+	})
+	return b.String()
+}
+
+// writeFrame draws a ruler and a box around the rows written by row.
+func writeFrame(b *bytes.Buffer, cols, rows int, row func(b *bytes.Buffer, y int)) {
+	numLen := len(strconv.Itoa(max(cols, rows)))
 	for j := range numLen {
 		b.WriteString(strings.Repeat(" ", numLen+2))
-		for i := 0; i < g.cols; i++ {
+		for i := 0; i < cols; i++ {
 			c := strconv.Itoa(i)
 			c = strings.Repeat(" ", numLen-len(c)) + c
 			b.WriteByte(c[j])
@@ -46,28 +66,26 @@ func (g *Grid) String() string {
 		b.WriteString("\n")
 	}
 	b.WriteString(strings.Repeat(" ", numLen+2))
-	b.WriteString(strings.Repeat("↓", g.cols))
+	b.WriteString(strings.Repeat("↓", cols))
 	b.WriteByte('\n')
 	b.WriteString(strings.Repeat(" ", numLen+1))
 	b.WriteString("┌")
-	b.WriteString(strings.Repeat("─", g.cols))
+	b.WriteString(strings.Repeat("─", cols))
 	b.WriteString("┐")
-	for i := 0; i < len(g.field); i = i + g.cols {
+	for y := range rows {
 		b.WriteByte('\n')
-		r := strconv.Itoa(i / g.cols)
+		r := strconv.Itoa(y)
 		r = strings.Repeat(" ", numLen-len(r)) + r + "→"
 		b.WriteString(r)
 		b.WriteString("│")
-		//b.Write(bytes.ReplaceAll(g.field[i:i+g.cols], []byte{'.'}, []byte{' '}))
-		b.Write(g.field[i : i+g.cols])
+		row(b, y)
 		b.WriteString("│")
 	}
 	b.WriteByte('\n')
 	b.WriteString(strings.Repeat(" ", numLen+1))
 	b.WriteString("└")
-	b.WriteString(strings.Repeat("─", g.cols))
+	b.WriteString(strings.Repeat("─", cols))
 	b.WriteString("┘")
-	return b.String()
 }
 
 func (g *Grid) p2i(c coord) (int, bool) {
@@ -151,6 +169,10 @@ func (c coord) Invert() coord {
 	return coord{x: -c.x, y: -c.y}
 }
 
+func (c coord) Mul(k int) coord {
+	return coord{x: c.x * k, y: c.y * k}
+}
+
 type coordPair struct {
 	c1, c2 coord
 }
@@ -179,14 +201,8 @@ func permute(v []coord) []coordPair {
 	return pairs
 }
 
-func runA(file string) error {
-	in, err := os.ReadFile(file)
-	if err != nil {
-		return err
-	}
-
-	g := NewGrid(in)
-	// find antennas
+// Antennas returns the positions of all antennas by their frequency.
+func (g *Grid) Antennas() map[string][]coord {
 	antennas := map[string][]coord{}
 	for i, v := range g.field {
 		if v == '.' {
@@ -194,88 +210,129 @@ func runA(file string) error {
 		}
 		antennas[string(v)] = append(antennas[string(v)], g.MustI2p(i))
 	}
+	return antennas
+}
 
-	fmt.Fprintf(debugWriter, "antennas: %+v\n", antennas)
-
-	for k, vv := range antennas {
-		if len(vv) < 2 {
-			continue
-		}
-		pairs := permute(vv)
-		fmt.Fprintf(debugWriter, "checking %s: %v\n", string(k), pairs)
-		for _, p := range pairs {
-			dist := p.Distance()
-			g.Set(p.c1.Add(dist), '#')
-			g.Set(p.c2.Add(dist.Invert()), '#')
-		}
-		fmt.Fprintf(debugWriter, "%s\n", g)
-	}
+func (g *Grid) Bounds() bounds {
+	return bounds{cols: g.cols, rows: len(g.field) / g.cols}
+}
 
-	anti := bytes.Count(g.field, []byte{'#'})
-	fmt.Println("antinodes", anti)
-	return nil
+type options struct {
+	input string
+	// additional model to count antinodes with, besides the ones of the puzzle
+	custom *antinodeModel
+	// print a report per frequency, either as table or json
+	report string
+	// draw the map with antennas and antinodes, either with glyphs or ansi colors
+	render string
+	// only draw antennas and antinodes of this frequency
+	frequency string
 }
 
-func runB(file string) error {
-	in, err := os.ReadFile(file)
+func run(opts options, name string, m antinodeModel) (coordSet, error) {
+	in, err := os.ReadFile(opts.input)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	g := NewGrid(in)
-	// find antennas
-	antennas := map[string][]coord{}
-	for i, v := range g.field {
-		if v == '.' {
-			continue
-		}
-		antennas[string(v)] = append(antennas[string(v)], g.MustI2p(i))
+	b, antennas, err := parseMap(in)
+	if err != nil {
+		return nil, err
+	}
+	found, r := analyze(name, antennas, m, b)
+	switch opts.report {
+	case reportTable:
+		err = r.WriteTable(os.Stdout)
+	case reportJSON:
+		err = r.WriteJSON(os.Stdout)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Fprintf(debugWriter, "antennas: %+v\n", antennas)
-
-	for k, vv := range antennas {
-		if len(vv) < 2 {
-			continue
-		}
-		pairs := permute(vv)
-		fmt.Fprintf(debugWriter, "checking %s: %v\n", string(k), pairs)
-		for _, p := range pairs {
-			dist := p.Distance()
-
-			pos := p.c1
-			for {
-				if _, ok := g.Set(pos, '#'); !ok {
-					break
-				}
-				pos = pos.Add(dist)
-			}
-
-			dist = p.Distance().Invert()
-			pos = p.c2
-			for {
-				if _, ok := g.Set(pos, '#'); !ok {
-					break
-				}
-				pos = pos.Add(dist)
-			}
+	if opts.render != renderNone {
+		l := newLayers(b, antennas, found)
+		if opts.frequency != "" {
+			positions := antennas[opts.frequency]
+			l = newLayers(b, map[string][]coord{opts.frequency: positions}, m.Antinodes(positions, b))
 		}
-		fmt.Fprintf(debugWriter, "%s\n", g)
+		fmt.Printf("%s:\n%s", name, l.Render(opts.render))
 	}
-	an := bytes.Count(g.field, []byte{'#'})
-	fmt.Println("antinodes", an)
-	return nil
+	return found, nil
 }
 
-var debugWriter = os.Stderr
+// parseHarmonics parses a range of multiples like "1-3", "2" or "0-" for no upper limit.
+func parseHarmonics(s string) (antinodeModel, error) {
+	m := antinodeModel{}
+	lo, hi, isRange := strings.Cut(s, "-")
+	var err error
+	if m.min, err = strconv.Atoi(lo); err != nil || m.min < 0 {
+		return m, fmt.Errorf("invalid minimum multiple %q", lo)
+	}
+	switch {
+	case !isRange:
+		m.max = m.min
+	case hi == "":
+		m.max = -1
+	default:
+		if m.max, err = strconv.Atoi(hi); err != nil || m.max < m.min {
+			return m, fmt.Errorf("invalid maximum multiple %q", hi)
+		}
+	}
+	return m, nil
+}
 
 func main() {
-	if err := runA("input.txt"); err != nil {
-		fmt.Fprintf(os.Stderr, "puzzle errored: %v\n", err)
+	opts := options{}
+	harmonics, lattice := "", false
+	flag.StringVar(&opts.input, "input", "input.txt", "puzzle input file, either a map or a sparse list with cols,rows in the first line and x,y,freq per antenna")
+	flag.StringVar(&harmonics, "harmonics", "", "additionally count antinodes at these multiples of the antenna distance, e.g. 1-3, 2 or 0- for no upper limit")
+	flag.BoolVar(&lattice, "lattice", false, "include all grid points in line with the antennas for -harmonics, also between them")
+	flag.StringVar(&opts.report, "report", reportNone, "print antennas, pairs and antinodes per frequency, either as table or json")
+	flag.StringVar(&opts.render, "render", renderNone, "draw the map with antennas and antinodes, either with glyphs or ansi colors")
+	flag.StringVar(&opts.frequency, "frequency", "", "only draw antennas and antinodes of this frequency with -render")
+	flag.Parse()
+	switch opts.report {
+	case reportNone, reportTable, reportJSON:
+	default:
+		fmt.Fprintf(os.Stderr, "invalid report format %s\n", opts.report)
 		os.Exit(1)
 	}
-	if err := runB("input.txt"); err != nil {
-		fmt.Fprintf(os.Stderr, "puzzle errored: %v\n", err)
+	switch opts.render {
+	case renderNone, renderGlyphs, renderANSI:
+	default:
+		fmt.Fprintf(os.Stderr, "invalid render style %s\n", opts.render)
 		os.Exit(1)
 	}
+	if harmonics != "" {
+		m, err := parseHarmonics(harmonics)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		m.lattice = lattice
+		opts.custom = &m
+	}
+
+	models := []struct {
+		name string
+		m    antinodeModel
+	}{{"A", partA}, {"B", partB}}
+	if opts.custom != nil {
+		models = append(models, struct {
+			name string
+			m    antinodeModel
+		}{harmonics, *opts.custom})
+	}
+	for _, m := range models {
+		found, err := run(opts, m.name, m.m)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "puzzle errored: %v\n", err)
+			os.Exit(1)
+		}
+		if opts.report != reportJSON {
+			// the json report contains the total already, keep the output parsable
+			fmt.Printf("%s: antinodes %d\n", m.name, len(found))
+		}
+	}
 }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const example = `............
+........0...
+.....0......
+.......0....
+....0.......
+......A.....
+............
+............
+........A...
+.........A..
+............
+............
+`
+
+func TestExample(t *testing.T) {
+	g, err := NewGrid([]byte(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := slices.Clone(g.field)
+	table := []struct {
+		model    antinodeModel
+		expected report
+	}{
+		{model: partA, expected: report{Name: "A", Antinodes: 14, Frequencies: []frequencyReport{
+			{Frequency: "0", Antennas: 4, Pairs: 6, Antinodes: 10, Unique: 9, Shared: 1},
+			{Frequency: "A", Antennas: 3, Pairs: 3, Antinodes: 5, Unique: 4, Shared: 1},
+		}}},
+		{model: partB, expected: report{Name: "B", Antinodes: 34, Frequencies: []frequencyReport{
+			{Frequency: "0", Antennas: 4, Pairs: 6, Antinodes: 21, Unique: 18, Shared: 3},
+			{Frequency: "A", Antennas: 3, Pairs: 3, Antinodes: 16, Unique: 13, Shared: 3},
+		}}},
+	}
+	for _, td := range table {
+		t.Run(td.expected.Name, func(t *testing.T) {
+			found, r := analyze(td.expected.Name, g.Antennas(), td.model, g.Bounds())
+			if len(found) != td.expected.Antinodes {
+				t.Errorf("expected %d antinodes, got %d", td.expected.Antinodes, len(found))
+			}
+			if r.Name != td.expected.Name || r.Antinodes != td.expected.Antinodes || !slices.Equal(r.Frequencies, td.expected.Frequencies) {
+				t.Errorf("expected report %+v, got %+v", td.expected, r)
+			}
+		})
+	}
+	if !slices.Equal(before, g.field) {
+		t.Errorf("grid changed:\n%s", g)
+	}
+}
+
+func TestAntinodes(t *testing.T) {
+	b := bounds{cols: 20, rows: 20}
+	antennas := []coord{{x: 4, y: 2}, {x: 0, y: 0}}
+	table := []struct {
+		model    antinodeModel
+		expected []coord
+	}{
+		{model: partA, expected: []coord{{x: 8, y: 4}}},
+		{model: partB, expected: []coord{{x: 0, y: 0}, {x: 4, y: 2}, {x: 8, y: 4}, {x: 12, y: 6}, {x: 16, y: 8}}},
+		{model: antinodeModel{min: 2, max: 3}, expected: []coord{{x: 12, y: 6}, {x: 16, y: 8}}},
+		{model: antinodeModel{min: 0, max: 0, lattice: true}, expected: []coord{{x: 0, y: 0}, {x: 2, y: 1}, {x: 4, y: 2}}},
+		{model: antinodeModel{min: 1, max: 1, lattice: true}, expected: []coord{{x: 2, y: 1}, {x: 8, y: 4}}},
+		{model: antinodeModel{min: 0, max: -1, lattice: true}, expected: []coord{
+			{x: 0, y: 0}, {x: 2, y: 1}, {x: 4, y: 2}, {x: 6, y: 3}, {x: 8, y: 4},
+			{x: 10, y: 5}, {x: 12, y: 6}, {x: 14, y: 7}, {x: 16, y: 8}, {x: 18, y: 9},
+		}},
+	}
+	for _, td := range table {
+		t.Run(fmt.Sprintf("%+v", td.model), func(t *testing.T) {
+			got := td.model.Antinodes(antennas, b).Sorted()
+			if !slices.Equal(td.expected, got) {
+				t.Fatalf("expected %v, got %v", td.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseHarmonics(t *testing.T) {
+	table := []struct {
+		in       string
+		expected antinodeModel
+		err      bool
+	}{
+		{in: "1", expected: antinodeModel{min: 1, max: 1}},
+		{in: "1-3", expected: antinodeModel{min: 1, max: 3}},
+		{in: "0-", expected: antinodeModel{min: 0, max: -1}},
+		{in: "3-1", err: true},
+		{in: "-1", err: true},
+		{in: "x", err: true},
+	}
+	for _, td := range table {
+		t.Run(td.in, func(t *testing.T) {
+			got, err := parseHarmonics(td.in)
+			if (err != nil) != td.err {
+				t.Fatalf("expected error %v, got %v", td.err, err)
+			}
+			if err == nil && got != td.expected {
+				t.Fatalf("expected %+v, got %+v", td.expected, got)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	antinodes := coordSet{}
+	antinodes.Add(coord{x: 1, y: 0})
+	antinodes.Add(coord{x: 2, y: 0})
+	l := newLayers(bounds{cols: 4, rows: 1}, map[string][]coord{"a": {{x: 0, y: 0}, {x: 1, y: 0}}}, antinodes)
+
+	glyphs := l.Render(renderGlyphs)
+	if !strings.Contains(glyphs, "│a*#.│") {
+		t.Errorf("expected row a*#. in\n%s", glyphs)
+	}
+	if !strings.HasSuffix(glyphs, "A antenna  # antinode  * antenna on antinode  . empty\n") {
+		t.Errorf("expected legend in\n%s", glyphs)
+	}
+
+	ansi := l.Render(renderANSI)
+	row := "│" + ansiAntenna + "a" + ansiReset + ansiOverlap + "a" + ansiReset + ansiAntinode + "#" + ansiReset + ".│"
+	if !strings.Contains(ansi, row) {
+		t.Errorf("expected row %q in\n%q", row, ansi)
+	}
+}
+
+func TestParseMap(t *testing.T) {
+	dense := func(in string) (bounds, map[string][]coord) {
+		b, antennas, err := parseMap([]byte(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b, antennas
+	}
+	sparse := "12,12\n8,1,0\n5,2,0\n7,3,0\n4,4,0\n6,5,A\n8,8,A\n9,9,A\n"
+	table := []struct {
+		name     string
+		in       string
+		bounds   bounds
+		antennas map[string][]coord
+	}{
+		{name: "single line", in: "..a.a", bounds: bounds{cols: 5, rows: 1}, antennas: map[string][]coord{"a": {{x: 2, y: 0}, {x: 4, y: 0}}}},
+		{name: "ragged", in: "a\n...b\n.a\n", bounds: bounds{cols: 4, rows: 3}, antennas: map[string][]coord{"a": {{x: 0, y: 0}, {x: 1, y: 2}}, "b": {{x: 3, y: 1}}}},
+		{name: "sparse", in: sparse, bounds: bounds{cols: 12, rows: 12}, antennas: func() map[string][]coord {
+			_, antennas := dense(example)
+			return antennas
+		}()},
+		{name: "sparse without antennas", in: "3,4", bounds: bounds{cols: 3, rows: 4}, antennas: map[string][]coord{}},
+	}
+	for _, td := range table {
+		t.Run(td.name, func(t *testing.T) {
+			b, antennas := dense(td.in)
+			if b != td.bounds {
+				t.Errorf("expected bounds %+v, got %+v", td.bounds, b)
+			}
+			if !maps.EqualFunc(td.antennas, antennas, slices.Equal) {
+				t.Errorf("expected antennas %v, got %v", td.antennas, antennas)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "\n", "3,x", "0,3", "3,3\n1,1", "3,3\n1,x,a", "3,3\n3,1,a", "3,3\n1,1,a\n1,1,b", "3,3\n1,1,", "4,2\n0,0,ab\n2,0,ab", "3,3\n1,1,."} {
+		if _, _, err := parseMap([]byte(in)); err == nil {
+			t.Errorf("expected error for %q", in)
+		}
+	}
+}
+
+func TestSparseLargeMap(t *testing.T) {
+	b, antennas, err := parseMap([]byte("1000000000,1000000000\n10,10,a\n20,30,a\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := partA.Antinodes(antennas["a"], b).Sorted()
+	// the antinode at (0,-10) is outside of the map
+	if expected := []coord{{x: 30, y: 50}}; !slices.Equal(expected, got) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	renderNone   = ""
+	renderGlyphs = "glyphs"
+	renderANSI   = "ansi"
+
+	ansiAntenna  = "\x1b[1;34m"
+	ansiAntinode = "\x1b[1;31m"
+	ansiOverlap  = "\x1b[1;37;41m"
+	ansiReset    = "\x1b[0m"
+
+	glyphEmpty    = '.'
+	glyphAntinode = '#'
+	glyphOverlap  = '*'
+)
+
+// layers are drawn on top of each other, antinodes over empty cells and antennas over antinodes.
+type layers struct {
+	bounds    bounds
+	antennas  map[coord]string
+	antinodes coordSet
+}
+
+func newLayers(b bounds, antennas map[string][]coord, antinodes coordSet) layers {
+	l := layers{bounds: b, antennas: map[coord]string{}, antinodes: antinodes}
+	for freq, positions := range antennas {
+		for _, c := range positions {
+			l.antennas[c] = freq
+		}
+	}
+	return l
+}
+
+// Render draws the map inside a ruler and box followed by a legend.
+// With renderGlyphs antennas on top of antinodes are drawn as glyphOverlap,
+// with renderANSI every layer has its own color and overlapping antennas keep their frequency.
+func (l layers) Render(style string) string {
+	buf := bytes.Buffer{}
+	writeFrame(&buf, l.bounds.cols, l.bounds.rows, func(b *bytes.Buffer, y int) {
+		for x := range l.bounds.cols {
+			c := coord{x: x, y: y}
+			freq, antenna := l.antennas[c]
+			_, antinode := l.antinodes[c]
+			switch {
+			case antenna && antinode && style == renderANSI:
+				b.WriteString(ansiOverlap + freq + ansiReset)
+			case antenna && antinode:
+				b.WriteByte(glyphOverlap)
+			case antenna && style == renderANSI:
+				b.WriteString(ansiAntenna + freq + ansiReset)
+			case antenna:
+				b.WriteString(freq)
+			case antinode && style == renderANSI:
+				b.WriteString(ansiAntinode + string(glyphAntinode) + ansiReset)
+			case antinode:
+				b.WriteByte(glyphAntinode)
+			default:
+				b.WriteByte(glyphEmpty)
+			}
+		}
+	})
+	buf.WriteByte('\n')
+
+	if style == renderANSI {
+		fmt.Fprintf(&buf, "%sA%s antenna  %s%c%s antinode  %sA%s antenna on antinode  %c empty\n",
+			ansiAntenna, ansiReset, ansiAntinode, glyphAntinode, ansiReset, ansiOverlap, ansiReset, glyphEmpty)
+	} else {
+		fmt.Fprintf(&buf, "A antenna  %c antinode  %c antenna on antinode  %c empty\n", glyphAntinode, glyphOverlap, glyphEmpty)
+	}
+	return buf.String()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+)
+
+const (
+	reportNone  = ""
+	reportTable = "table"
+	reportJSON  = "json"
+)
+
+// frequencyReport summarizes the antinodes created by the antennas of one frequency.
+type frequencyReport struct {
+	Frequency string `json:"frequency"`
+	Antennas  int    `json:"antennas"`
+	Pairs     int    `json:"pairs"`
+	Antinodes int    `json:"antinodes"`
+	// antinodes no other frequency creates
+	Unique int `json:"unique"`
+	// antinodes at least one other frequency creates as well
+	Shared int `json:"shared"`
+}
+
+// report lists the frequencies ordered by name, together with the total number of antinodes.
+type report struct {
+	Name        string            `json:"name"`
+	Antinodes   int               `json:"antinodes"`
+	Frequencies []frequencyReport `json:"frequencies"`
+}
+
+// analyze calculates the antinodes of all frequencies, returning them as a set and per frequency.
+func analyze(name string, antennas map[string][]coord, m antinodeModel, b bounds) (coordSet, report) {
+	perFreq := map[string]coordSet{}
+	// number of frequencies creating an antinode at each position
+	count := map[coord]int{}
+	for freq, positions := range antennas {
+		found := m.Antinodes(positions, b)
+		perFreq[freq] = found
+		for c := range found {
+			count[c]++
+		}
+	}
+
+	r := report{Name: name, Antinodes: len(count)}
+	for _, freq := range slices.Sorted(maps.Keys(antennas)) {
+		n := len(antennas[freq])
+		fr := frequencyReport{
+			Frequency: freq,
+			Antennas:  n,
+			Pairs:     n * (n - 1) / 2,
+			Antinodes: len(perFreq[freq]),
+		}
+		for c := range perFreq[freq] {
+			if count[c] == 1 {
+				fr.Unique++
+			} else {
+				fr.Shared++
+			}
+		}
+		r.Frequencies = append(r.Frequencies, fr)
+	}
+
+	all := coordSet{}
+	for c := range count {
+		all.Add(c)
+	}
+	return all, r
+}
+
+func (r report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%s:\n", r.Name)
+	fmt.Fprintf(tw, "frequency\tantennas\tpairs\tantinodes\tunique\tshared\t\n")
+	for _, f := range r.Frequencies {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t\n", f.Frequency, f.Antennas, f.Pairs, f.Antinodes, f.Unique, f.Shared)
+	}
+	fmt.Fprintf(tw, "total\t\t\t%d\t\t\t\n", r.Antinodes)
+	return tw.Flush()
+}
+
+func (r report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseMap returns the bounds of the map and its antennas by frequency. The input is either a map
+// with one row per line, or a sparse list of antennas when the first line contains a ','.
+func parseMap(in []byte) (bounds, map[string][]coord, error) {
+	first, _, _ := bytes.Cut(in, []byte{'\n'})
+	if bytes.ContainsRune(first, ',') {
+		return parseSparse(string(in))
+	}
+	g, err := NewGrid(in)
+	if err != nil {
+		return bounds{}, nil, err
+	}
+	return g.Bounds(), g.Antennas(), nil
+}
+
+// parseSparse reads maps given as a list of antennas, without allocating the cells of the map.
+// The first line holds the size of the map as "cols,rows", every other line one antenna as "x,y,freq",
+// with freq being a single character as in a map.
+func parseSparse(in string) (bounds, map[string][]coord, error) {
+	lines := strings.Split(strings.TrimRight(in, "\n"), "\n")
+	b := bounds{}
+	fields := strings.Split(lines[0], ",")
+	if len(fields) != 2 {
+		return b, nil, fmt.Errorf("expected bounds as cols,rows, got %q", lines[0])
+	}
+	var err error
+	if b.cols, err = strconv.Atoi(fields[0]); err != nil || b.cols <= 0 {
+		return b, nil, fmt.Errorf("invalid number of columns %q", fields[0])
+	}
+	if b.rows, err = strconv.Atoi(fields[1]); err != nil || b.rows <= 0 {
+		return b, nil, fmt.Errorf("invalid number of rows %q", fields[1])
+	}
+
+	antennas := map[string][]coord{}
+	seen := map[coord]string{}
+	for i, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 {
+			return b, nil, fmt.Errorf("line %d: expected antenna as x,y,freq, got %q", i+2, line)
+		}
+		if len(fields[2]) != 1 || fields[2] == "." {
+			// like in a map, every frequency takes exactly one cell
+			return b, nil, fmt.Errorf("line %d: frequency must be a single character other than '.', got %q", i+2, fields[2])
+		}
+		c := coord{}
+		if c.x, err = strconv.Atoi(fields[0]); err != nil {
+			return b, nil, fmt.Errorf("line %d: invalid x %q: %w", i+2, fields[0], err)
+		}
+		if c.y, err = strconv.Atoi(fields[1]); err != nil {
+			return b, nil, fmt.Errorf("line %d: invalid y %q: %w", i+2, fields[1], err)
+		}
+		if !b.In(c) {
+			return b, nil, fmt.Errorf("line %d: antenna at %s outside of %dx%d map", i+2, c, b.cols, b.rows)
+		}
+		if other, ok := seen[c]; ok {
+			return b, nil, fmt.Errorf("line %d: antenna %s at %s already taken by %s", i+2, fields[2], c, other)
+		}
+		seen[c] = fields[2]
+		antennas[fields[2]] = append(antennas[fields[2]], c)
+	}
+	return b, antennas, nil
+}
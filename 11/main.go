@@ -1,13 +1,15 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+
+	"github.com/floj/aoc2024/lib/memo"
 )
 
 func main() {
@@ -24,22 +26,18 @@ func main() {
 
 }
 
-var seqCache = &sync.Map{}
+type seqKey struct {
+	remaining, s int
+}
+
+var seqCache = memo.NewSync[seqKey, uint64](0)
 
 func getCache(remaining, s int) (uint64, bool) {
-	b := bytes.Buffer{}
-	fmt.Fprint(&b, remaining, '|', s)
-	if v, ok := seqCache.Load(b.String()); ok {
-		return v.(uint64), true
-	}
-	return 0, false
+	return seqCache.Get(seqKey{remaining: remaining, s: s})
 }
 
 func setCache(remaining, s int, v uint64) uint64 {
-	b := bytes.Buffer{}
-	fmt.Fprint(&b, remaining, '|', s)
-	seqCache.LoadOrStore(b.String(), v)
-	return v
+	return seqCache.Set(seqKey{remaining: remaining, s: s}, v)
 }
 
 func CountStones(s, remaining int) uint64 {
@@ -109,5 +107,8 @@ func runA(file string, blinks int) error {
 	wg.Wait()
 
 	fmt.Fprintf(os.Stderr, "number of stones after %d blinks: %d\n", blinks, sum.Load())
+	fmt.Fprintf(debugOut, "sequence cache: %s\n", seqCache.Stats())
 	return nil
 }
+
+var debugOut = io.Discard
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"sort"
+
+	"github.com/floj/aoc2024/lib/geom"
 )
 
 func main() {
@@ -52,7 +54,7 @@ func (g *Grid) p2i(x, y int) (int, bool) {
 	if x < 0 || y < 0 {
 		return -1, false
 	}
-	if c.x >= g.cols {
+	if x >= g.cols {
 		return -1, false
 	}
 	idx := y*g.cols + x
@@ -93,19 +95,6 @@ func (g *Grid) Set(x, y int, v byte) (byte, bool) {
 	return 0, false
 }
 
-type Neighbor struct {
-	x       int
-	y       int
-	bitmask byte
-}
-
-var neighbors = map[string]Neighbor{
-	"top":    {x: 0, y: -1, bitmask: 0b0100},
-	"bottom": {x: 0, y: +1, bitmask: 0b0001},
-	"right":  {x: +1, y: 0, bitmask: 0b0010},
-	"left":   {x: -1, y: 0, bitmask: 0b1000},
-}
-
 func (g *Grid) FloodFill(idx int, v byte) []int {
 	// out of bounds
 	if idx < 0 || idx >= len(g.field) {
@@ -122,8 +111,9 @@ func (g *Grid) FloodFill(idx int, v byte) []int {
 	// matches, fill field
 	pos := []int{idx}
 	g.visited[idx]++
-	for _, n := range neighbors {
-		pos = append(pos, g.FloodFill(idx+n.x+(g.cols*n.y), v)...)
+	for _, d := range geom.Dirs {
+		n := d.Vec()
+		pos = append(pos, g.FloodFill(idx+n.X+(g.cols*n.Y), v)...)
 	}
 	sort.Ints(pos)
 	return pos
@@ -189,8 +179,9 @@ func (r *Rect) Perimeter() int {
 			continue
 		}
 		ix, iy := i%r.cols, i/r.cols
-		for _, n := range neighbors {
-			nx, ny := ix+n.x, iy+n.y
+		for _, d := range geom.Dirs {
+			n := d.Vec()
+			nx, ny := ix+n.X, iy+n.Y
 			npos := ny*r.cols + nx
 			if nx < 0 || nx >= r.cols {
 				r.peri++
@@ -235,39 +226,40 @@ func (r *Rect) SidesOuter() int {
 			continue
 		}
 		ix, iy := i%r.cols, i/r.cols
-		for _, n := range neighbors {
-			nx, ny := ix+n.x, iy+n.y
+		for _, d := range geom.Dirs {
+			n := d.Vec()
+			nx, ny := ix+n.X, iy+n.Y
 			npos := ny*r.cols + nx
 			if nx < 0 || nx >= r.cols {
-				sides[i] = sides[i] | n.bitmask
+				sides[i] = sides[i] | d.Bit()
 				continue
 			}
 			if npos < 0 || npos >= len(r.field) {
-				sides[i] = sides[i] | n.bitmask
+				sides[i] = sides[i] | d.Bit()
 				continue
 			}
 
 			// other area, use fence
 			if r.field[npos] != v {
-				sides[i] = sides[i] | n.bitmask
+				sides[i] = sides[i] | d.Bit()
 				continue
 			}
 			// else same area, no fence
 		}
 	}
 
-	corners := [][]string{
+	corners := [][2]geom.Dir{
 		// outer corners
-		{"top", "left"},
-		{"top", "right"},
-		{"bottom", "right"},
-		{"bottom", "left"},
+		{geom.North, geom.West},
+		{geom.North, geom.East},
+		{geom.South, geom.East},
+		{geom.South, geom.West},
 	}
-	touch := map[string]touchpoint{
-		"top-left":     {offX: -1, offY: -1, bm: neighbors["bottom"].bitmask | neighbors["right"].bitmask},
-		"top-right":    {offX: +1, offY: -1, bm: neighbors["bottom"].bitmask | neighbors["left"].bitmask},
-		"bottom-left":  {offX: -1, offY: +1, bm: neighbors["top"].bitmask | neighbors["right"].bitmask},
-		"bottom-right": {offX: +1, offY: +1, bm: neighbors["top"].bitmask | neighbors["left"].bitmask},
+	touch := map[[2]geom.Dir]touchpoint{
+		{geom.North, geom.West}: {offX: -1, offY: -1, bm: geom.South.Bit() | geom.East.Bit()},
+		{geom.North, geom.East}: {offX: +1, offY: -1, bm: geom.South.Bit() | geom.West.Bit()},
+		{geom.South, geom.West}: {offX: -1, offY: +1, bm: geom.North.Bit() | geom.East.Bit()},
+		{geom.South, geom.East}: {offX: +1, offY: +1, bm: geom.North.Bit() | geom.West.Bit()},
 	}
 
 	// count corners, this is equivalent to sides
@@ -275,14 +267,14 @@ func (r *Rect) SidesOuter() int {
 		ix, iy := i%r.cols, i/r.cols
 
 		for _, c := range corners {
-			bm := neighbors[c[0]].bitmask | neighbors[c[1]].bitmask
+			bm := c[0].Bit() | c[1].Bit()
 			res := v & bm
 			if res == bm {
 				r.sidesO++
 				fmt.Printf("outer %d %d %s-%s mask=%04b v=%04b r=%04b %t %d\n", ix, iy, c[0], c[1], bm, v, res, res == bm, r.sidesO)
 
 				// hack! see if another corner is touching
-				if t, ok := touch[c[0]+"-"+c[1]]; ok {
+				if t, ok := touch[c]; ok {
 					tx, ty := ix+t.offX, iy+t.offY
 
 					tpos := ty*r.cols + tx
@@ -315,8 +307,9 @@ func (r *Rect) SidesInner() int {
 			continue
 		}
 		ix, iy := i%r.cols, i/r.cols
-		for _, n := range neighbors {
-			nx, ny := ix+n.x, iy+n.y
+		for _, d := range geom.Dirs {
+			n := d.Vec()
+			nx, ny := ix+n.X, iy+n.Y
 			npos := ny*r.cols + nx
 			if nx < 0 || nx >= r.cols {
 				continue
@@ -327,17 +320,17 @@ func (r *Rect) SidesInner() int {
 			if r.field[npos] == v {
 				continue
 			}
-			sides[i] = sides[i] | n.bitmask
+			sides[i] = sides[i] | d.Bit()
 			// else same area, no fence
 		}
 	}
 
-	corners := [][]string{
+	corners := [][2]geom.Dir{
 		// outer corners
-		{"top", "left"},
-		{"top", "right"},
-		{"bottom", "right"},
-		{"bottom", "left"},
+		{geom.North, geom.West},
+		{geom.North, geom.East},
+		{geom.South, geom.East},
+		{geom.South, geom.West},
 	}
 
 	// count corners, this is equivalent to sides
@@ -345,7 +338,7 @@ func (r *Rect) SidesInner() int {
 		ix, iy := i%r.cols, i/r.cols
 
 		for _, c := range corners {
-			bm := neighbors[c[0]].bitmask | neighbors[c[1]].bitmask
+			bm := c[0].Bit() | c[1].Bit()
 			res := v & bm
 			if res == bm {
 				r.sidesI++
@@ -6,6 +6,8 @@ import (
 	"os"
 	"slices"
 	"strconv"
+
+	"github.com/floj/aoc2024/lib/geom"
 )
 
 type Grid struct {
@@ -64,49 +66,49 @@ func (g *Grid) String() string {
 	return b.String()
 }
 
-func (g *Grid) p2i(c coord) (int, bool) {
-	if c.x < 0 || c.y < 0 {
+func (g *Grid) p2i(c geom.Vec) (int, bool) {
+	if c.X < 0 || c.Y < 0 {
 		return -1, false
 	}
-	if c.x >= g.cols {
+	if c.X >= g.cols {
 		return -1, false
 	}
-	idx := c.y*g.cols + c.x
+	idx := c.Index(g.cols)
 	if idx >= len(g.field) {
 		return -1, false
 	}
 	return idx, true
 }
 
-func (g *Grid) i2p(idx int) (coord, bool) {
+func (g *Grid) i2p(idx int) (geom.Vec, bool) {
 	if idx < 0 || idx >= len(g.field) {
-		return coord{}, false
+		return geom.Vec{}, false
 	}
-	return coord{x: idx % g.cols, y: idx / g.cols}, true
+	return geom.FromIndex(idx, g.cols), true
 }
 
-func (g *Grid) MustI2p(idx int) coord {
+func (g *Grid) MustI2p(idx int) geom.Vec {
 	if c, ok := g.i2p(idx); ok {
 		return c
 	}
 	panic("could not convert index to coordinate: " + strconv.Itoa(idx))
 }
 
-func (g *Grid) Get(c coord) (byte, bool) {
+func (g *Grid) Get(c geom.Vec) (byte, bool) {
 	if idx, ok := g.p2i(c); ok {
 		return g.field[idx], true
 	}
 	return 0, false
 }
 
-func (g *Grid) MustGet(c coord) byte {
+func (g *Grid) MustGet(c geom.Vec) byte {
 	if v, ok := g.Get(c); ok {
 		return v
 	}
 	panic("could not get " + c.String())
 }
 
-func (g *Grid) Set(c coord, v byte) (byte, bool) {
+func (g *Grid) Set(c geom.Vec, v byte) (byte, bool) {
 	if idx, ok := g.p2i(c); ok {
 		fmt.Printf("drawing %s with %s\n", c, string(v))
 		o := g.field[idx]
@@ -116,55 +118,21 @@ func (g *Grid) Set(c coord, v byte) (byte, bool) {
 	return 0, false
 }
 
-type coord struct {
-	x, y int
-}
-
-func (c coord) String() string {
-	return fmt.Sprintf("(%d,%d)", c.x, c.y)
-}
-
-func (c coord) Add(o coord) coord {
-	return coord{x: c.x + o.x, y: c.y + o.y}
+func GPS(c geom.Vec) int {
+	return c.Y*100 + c.X
 }
 
-func (c coord) AddX(offset int) coord {
-	return coord{x: c.x + offset, y: c.y}
-}
-
-func (c coord) AddY(offset int) coord {
-	return coord{x: c.x, y: c.y + offset}
-}
-
-func (c coord) AddXY(offsetX, offsetY int) coord {
-	return coord{x: c.x + offsetX, y: c.y + offsetY}
-}
-
-func (c coord) GPS() int {
-	return c.y*100 + c.x
-}
-
-var offsets = map[byte]coord{
-	'^': {x: 0, y: -1},
-	'>': {x: 1, y: 0},
-	'v': {x: 0, y: 1},
-	'<': {x: -1, y: 0},
-}
-
-func (g *Grid) Robot() (coord, bool) {
+func (g *Grid) Robot() (geom.Vec, bool) {
 	rPos := bytes.IndexByte(g.field, '@')
 	if rPos < 0 {
-		return coord{}, false
+		return geom.Vec{}, false
 	}
 	rC, ok := g.i2p(rPos)
 	return rC, ok
 }
 
-func (g *Grid) MoveWarehouse1(srcC coord, direction byte) bool {
-	off, found := offsets[direction]
-	if !found {
-		panic("invalid direction")
-	}
+func (g *Grid) MoveWarehouse1(srcC geom.Vec, direction geom.Dir) bool {
+	off := direction.Vec()
 
 	srcV, ok := g.Get(srcC)
 	if !ok {
@@ -198,11 +166,8 @@ func (g *Grid) MoveWarehouse1(srcC coord, direction byte) bool {
 	}
 }
 
-func (g *Grid) MoveWarehouse2(indent string, srcC coord, direction byte) bool {
-	off, found := offsets[direction]
-	if !found {
-		panic("invalid direction")
-	}
+func (g *Grid) MoveWarehouse2(indent string, srcC geom.Vec, direction geom.Dir) bool {
+	off := direction.Vec()
 
 	srcV := g.MustGet(srcC)
 
@@ -246,60 +211,62 @@ func (g *Grid) MoveWarehouse2(indent string, srcC coord, direction byte) bool {
 	}
 }
 
-func (g *Grid) MoveBox(indent string, srcC, off coord, direction byte) bool {
+func (g *Grid) MoveBox(indent string, srcC, off geom.Vec, direction geom.Dir) bool {
 	// get top left corner of box
 	srcV, ok := g.Get(srcC)
 	if !ok {
 		panic("invalid srcC  " + srcC.String())
 	}
 	if srcV == ']' {
-		return g.MoveBox(indent, srcC.AddX(-1), off, direction)
+		return g.MoveBox(indent, srcC.Step(geom.West, 1), off, direction)
 	}
 	if srcV != '[' {
 		panic("not a box " + srcC.String())
 	}
 
 	destC := srcC.Add(off)
-	fmt.Printf("%smoving box src=%s dest=%s srcV=%s direction=%s\n", indent, srcC, destC, string(srcV), string(direction))
+	// right half of the box, before and after moving
+	srcR, destR := srcC.Step(geom.East, 1), destC.Step(geom.East, 1)
+	fmt.Printf("%smoving box src=%s dest=%s srcV=%s direction=%s\n", indent, srcC, destC, string(srcV), string(direction.Arrow()))
 
 	// when moving a box left or right, just check the one new tile that will be taken
 	switch direction {
-	case '^':
+	case geom.North:
 		fallthrough
-	case 'v':
+	case geom.South:
 		if !g.MoveWarehouse2(indent+"  ", destC, direction) {
 			fmt.Printf(" -> can't move boxL %s\n", destC)
 			return false
 		}
-		if !g.MoveWarehouse2(indent+"  ", destC.AddX(1), direction) {
-			fmt.Printf(" -> can't move boxR %s\n", destC.AddX(1))
+		if !g.MoveWarehouse2(indent+"  ", destR, direction) {
+			fmt.Printf(" -> can't move boxR %s\n", destR)
 			return false
 		}
 		g.Set(destC, '[')
-		g.Set(destC.AddX(1), ']')
+		g.Set(destR, ']')
 		g.Set(srcC, '.')
-		g.Set(srcC.AddX(1), '.')
+		g.Set(srcR, '.')
 		return true
-	case '<':
+	case geom.West:
 		if !g.MoveWarehouse2(indent+"  ", destC, direction) {
 			fmt.Printf("%s-> can't move box %s\n", indent, destC)
 			return false
 		}
 		g.Set(destC, '[')
-		g.Set(destC.AddX(1), ']')
-		g.Set(srcC.AddX(1), '.')
+		g.Set(destR, ']')
+		g.Set(srcR, '.')
 		return true
-	case '>':
-		if !g.MoveWarehouse2(indent+"  ", destC.AddX(1), direction) {
-			fmt.Printf("%s-> can't move %s\n", indent, destC.AddX(1))
+	case geom.East:
+		if !g.MoveWarehouse2(indent+"  ", destR, direction) {
+			fmt.Printf("%s-> can't move %s\n", indent, destR)
 			return false
 		}
 		g.Set(destC, '[')
-		g.Set(destC.AddX(1), ']')
+		g.Set(destR, ']')
 		g.Set(srcC, '.')
 		return true
 	default:
-		panic("invalid direction: " + string(direction))
+		panic("invalid direction: " + direction.String())
 	}
 }
 
@@ -326,7 +293,7 @@ func runA(file string) (int, error) {
 		if !ok {
 			return -1, fmt.Errorf("invalid robot position")
 		}
-		g.MoveWarehouse1(rC, m)
+		g.MoveWarehouse1(rC, geom.MustParseArrow(m))
 		fmt.Printf("move %d %s %s\n", i+1, rC, string(m))
 		// fmt.Println(g)
 	}
@@ -338,7 +305,7 @@ func runA(file string) (int, error) {
 			continue
 		}
 		if c, ok := g.i2p(i); ok {
-			sumA += c.GPS()
+			sumA += GPS(c)
 		}
 
 	}
@@ -392,7 +359,7 @@ func runB(file string) (int, error) {
 		fmt.Printf("move %d %s %s\n", i+1, rC, string(m))
 
 		newG := g.Clone()
-		ok = newG.MoveWarehouse2("", rC, m)
+		ok = newG.MoveWarehouse2("", rC, geom.MustParseArrow(m))
 		if !ok {
 			// do nothing
 			continue
@@ -414,7 +381,7 @@ func runB(file string) (int, error) {
 	for i, v := range g.field {
 		if v == 'O' || v == '[' {
 			if c, ok := g.i2p(i); ok {
-				sumA += c.GPS()
+				sumA += GPS(c)
 			}
 		}
 	}
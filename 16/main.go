@@ -7,6 +7,8 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/floj/aoc2024/lib/geom"
 )
 
 type Grid struct {
@@ -59,56 +61,56 @@ func (g *Grid) String() string {
 	return b.String()
 }
 
-func (g *Grid) p2i(c coord) (int, bool) {
-	if c.x < 0 || c.y < 0 {
+func (g *Grid) p2i(c geom.Vec) (int, bool) {
+	if c.X < 0 || c.Y < 0 {
 		return -1, false
 	}
-	if c.x >= g.cols {
+	if c.X >= g.cols {
 		return -1, false
 	}
-	idx := c.y*g.cols + c.x
+	idx := c.Index(g.cols)
 	if idx >= len(g.field) {
 		return -1, false
 	}
 	return idx, true
 }
 
-func (g *Grid) MustP2i(c coord) int {
+func (g *Grid) MustP2i(c geom.Vec) int {
 	if idx, ok := g.p2i(c); ok {
 		return idx
 	}
 	panic("could not convert coordinate to index: " + c.String())
 }
 
-func (g *Grid) i2p(idx int) (coord, bool) {
+func (g *Grid) i2p(idx int) (geom.Vec, bool) {
 	if idx < 0 || idx >= len(g.field) {
-		return coord{}, false
+		return geom.Vec{}, false
 	}
-	return coord{x: idx % g.cols, y: idx / g.cols}, true
+	return geom.FromIndex(idx, g.cols), true
 }
 
-func (g *Grid) MustI2p(idx int) coord {
+func (g *Grid) MustI2p(idx int) geom.Vec {
 	if c, ok := g.i2p(idx); ok {
 		return c
 	}
 	panic("could not convert index to coordinate: " + strconv.Itoa(idx))
 }
 
-func (g *Grid) Get(c coord) (byte, bool) {
+func (g *Grid) Get(c geom.Vec) (byte, bool) {
 	if idx, ok := g.p2i(c); ok {
 		return g.field[idx], true
 	}
 	return 0, false
 }
 
-func (g *Grid) MustGet(c coord) byte {
+func (g *Grid) MustGet(c geom.Vec) byte {
 	if v, ok := g.Get(c); ok {
 		return v
 	}
 	panic("could not get " + c.String())
 }
 
-func (g *Grid) Set(c coord, v byte) (byte, bool) {
+func (g *Grid) Set(c geom.Vec, v byte) (byte, bool) {
 	if idx, ok := g.p2i(c); ok {
 		o := g.field[idx]
 		g.field[idx] = v
@@ -117,54 +119,37 @@ func (g *Grid) Set(c coord, v byte) (byte, bool) {
 	return 0, false
 }
 
-func (g *Grid) MustSet(c coord, v byte) byte {
+func (g *Grid) MustSet(c geom.Vec, v byte) byte {
 	if p, ok := g.Set(c, v); ok {
 		return p
 	}
 	panic("could not set " + c.String())
 }
 
-type turnDef struct {
-	direction byte
-	c         coord
-}
-
-var turnDefs = []turnDef{
-	{c: coord{x: 0, y: -1}, direction: '^'},
-	{c: coord{x: 1, y: 0}, direction: '>'},
-	{c: coord{x: 0, y: 1}, direction: 'v'},
-	{c: coord{x: -1, y: 0}, direction: '<'},
-}
-
-func turns(direction byte) []turnDef {
-	cur := slices.IndexFunc(turnDefs, func(e turnDef) bool {
-		return e.direction == direction
-	})
-	if cur < 0 {
-		panic("invalid direction " + string(direction))
-	}
-	l := len(turnDefs)
-	return []turnDef{
-		turnDefs[cur],
-		turnDefs[(l+cur-1)%l],
-		turnDefs[(l+cur+1)%l],
+// turns returns the directions a reindeer heading into direction can take next:
+// straight on, turning left or turning right
+func turns(direction geom.Dir) []geom.Dir {
+	return []geom.Dir{
+		direction,
+		direction.Left(),
+		direction.Right(),
 	}
 }
 
 type Node struct {
-	c         coord
+	c         geom.Vec
 	parent    *Node
-	direction byte
+	direction geom.Dir
 	score     int
 	cost      int
 }
 
 func (n *Node) ID() string {
-	return n.c.String() + string(n.direction)
+	return n.c.String() + string(n.direction.Arrow())
 }
 
 func (n *Node) String() string {
-	return fmt.Sprintf("%s %s (%d/%d)", n.c, string(n.direction), n.cost, n.score)
+	return fmt.Sprintf("%s %s (%d/%d)", n.c, string(n.direction.Arrow()), n.cost, n.score)
 }
 
 func (n *Node) GetPath() []*Node {
@@ -178,18 +163,17 @@ func (n *Node) GetPath() []*Node {
 
 // use a* to find shortest path
 // implementation from https://de.wikipedia.org/wiki/A*-Algorithmus
-func (g *Grid) Solve(startC, endC coord) ([]*Node, bool) {
-	openList := []*Node{{c: startC, direction: '>'}}
+func (g *Grid) Solve(startC, endC geom.Vec) ([]*Node, bool) {
+	openList := []*Node{{c: startC, direction: geom.East}}
 	closedList := map[string]bool{}
 
 	// add all walls to the closed list (no need to investigate)
 	for idx, v := range g.field {
 		if v == '#' {
 			c := g.MustI2p(idx)
-			closedList[c.String()+"^"] = true
-			closedList[c.String()+">"] = true
-			closedList[c.String()+"v"] = true
-			closedList[c.String()+"<"] = true
+			for _, d := range geom.Dirs {
+				closedList[c.String()+string(d.Arrow())] = true
+			}
 		}
 	}
 
@@ -219,12 +203,12 @@ func (g *Grid) Solve(startC, endC coord) ([]*Node, bool) {
 			// node is in closed list, skip
 
 			cost := 1
-			if currentNode.direction != t.direction {
+			if currentNode.direction != t {
 				cost += 1000
 			}
 			neighborN := &Node{
-				c:         currentNode.c.Add(t.c),
-				direction: t.direction,
+				c:         currentNode.c.Add(t.Vec()),
+				direction: t,
 				parent:    currentNode,
 				cost:      cost,
 				score:     currentNode.score + cost,
@@ -242,18 +226,6 @@ func (g *Grid) Solve(startC, endC coord) ([]*Node, bool) {
 	return bestPaths, len(bestPaths) > 0
 }
 
-type coord struct {
-	x, y int
-}
-
-func (c coord) String() string {
-	return fmt.Sprintf("(%d,%d)", c.x, c.y)
-}
-
-func (c coord) Add(o coord) coord {
-	return coord{x: c.x + o.x, y: c.y + o.y}
-}
-
 func run(file string) (int, error) {
 	in, err := os.ReadFile(file)
 	if err != nil {
@@ -0,0 +1,7 @@
+module github.com/floj/aoc2024/19
+
+go 1.23.4
+
+require github.com/floj/aoc2024/lib v0.0.0
+
+replace github.com/floj/aoc2024/lib => ../lib
@@ -8,6 +8,8 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/floj/aoc2024/lib/memo"
 )
 
 type Input struct {
@@ -73,10 +75,13 @@ func matchPattern(num int, pattern string, towels []string) int {
 
 	fmt.Fprintf(debugOut, "%v\n", candidates)
 
-	return tryMatch([]string{strconv.Itoa(num)}, map[string]int{}, pattern, pattern, candidates)
+	suffixCache := memo.New[string, int](0)
+	sum := tryMatch([]string{strconv.Itoa(num)}, suffixCache, pattern, pattern, candidates)
+	fmt.Fprintf(debugOut, "suffix cache: %s\n", suffixCache.Stats())
+	return sum
 }
 
-func tryMatch(progress []string, suffixCache map[string]int, org, test string, towels []string) int {
+func tryMatch(progress []string, suffixCache *memo.Cache[string, int], org, test string, towels []string) int {
 	if len(test) == 0 {
 		return 1
 	}
@@ -87,13 +92,9 @@ func tryMatch(progress []string, suffixCache map[string]int, org, test string, t
 			continue
 		}
 		remaining := test[len(t):]
-		if cache, hit := suffixCache[remaining]; hit {
-			sum += cache
-			continue
-		}
-		s := tryMatch(append(progress[:], t), suffixCache, org, remaining, towels)
-		suffixCache[remaining] = s
-		sum += s
+		sum += suffixCache.Do(remaining, func() int {
+			return tryMatch(append(progress[:], t), suffixCache, org, remaining, towels)
+		})
 	}
 	return sum
 }
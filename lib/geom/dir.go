@@ -0,0 +1,82 @@
+package geom
+
+// Dir is one of the four cardinal directions on a grid where y grows downwards.
+type Dir uint8
+
+const (
+	North Dir = iota
+	East
+	South
+	West
+)
+
+// Dirs lists all directions in clockwise order, starting with North.
+var Dirs = [...]Dir{North, East, South, West}
+
+var arrows = [...]byte{'^', '>', 'v', '<'}
+
+var names = [...]string{"north", "east", "south", "west"}
+
+var vecs = [...]Vec{
+	{X: 0, Y: -1},
+	{X: 1, Y: 0},
+	{X: 0, Y: 1},
+	{X: -1, Y: 0},
+}
+
+// ParseArrow converts one of '^', '>', 'v', '<' to a Dir.
+func ParseArrow(b byte) (Dir, bool) {
+	for d, a := range arrows {
+		if a == b {
+			return Dir(d), true
+		}
+	}
+	return 0, false
+}
+
+// MustParseArrow is like ParseArrow, but panics on unknown arrows.
+func MustParseArrow(b byte) Dir {
+	if d, ok := ParseArrow(b); ok {
+		return d
+	}
+	panic("invalid direction arrow: " + string(b))
+}
+
+// Right returns the direction after a 90 degree clockwise turn.
+func (d Dir) Right() Dir {
+	return (d + 1) % 4
+}
+
+// Left returns the direction after a 90 degree counter clockwise turn.
+func (d Dir) Left() Dir {
+	return (d + 3) % 4
+}
+
+// Reverse returns the opposite direction.
+func (d Dir) Reverse() Dir {
+	return (d + 2) % 4
+}
+
+// Arrow returns the arrow symbol of the direction as used in the puzzle inputs.
+func (d Dir) Arrow() byte {
+	return arrows[d%4]
+}
+
+// Bit returns a distinct single bit for each direction, e.g. to track visited directions in a bitmask.
+func (d Dir) Bit() uint8 {
+	return 1 << (d % 4)
+}
+
+// Vec returns the unit vector pointing in the direction.
+func (d Dir) Vec() Vec {
+	return vecs[d%4]
+}
+
+// Horizontal reports whether d is East or West.
+func (d Dir) Horizontal() bool {
+	return d == East || d == West
+}
+
+func (d Dir) String() string {
+	return names[d%4]
+}
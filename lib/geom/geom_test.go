@@ -0,0 +1,39 @@
+package geom
+
+import "testing"
+
+func TestDir(t *testing.T) {
+	for _, d := range Dirs {
+		if d.Right().Left() != d {
+			t.Fatalf("%s: right then left should be a no-op", d)
+		}
+		if d.Right().Right() != d.Reverse() {
+			t.Fatalf("%s: two right turns should reverse", d)
+		}
+		if d.Vec().Add(d.Reverse().Vec()) != (Vec{}) {
+			t.Fatalf("%s: vector of reverse direction should cancel out", d)
+		}
+		if p, ok := ParseArrow(d.Arrow()); !ok || p != d {
+			t.Fatalf("%s: arrow %c does not parse back", d, d.Arrow())
+		}
+	}
+	if _, ok := ParseArrow('x'); ok {
+		t.Fatalf("x should not parse as arrow")
+	}
+}
+
+func TestVec(t *testing.T) {
+	a, b := Vec{X: 1, Y: 2}, Vec{X: 4, Y: -2}
+	if d := a.Manhattan(b); d != 7 {
+		t.Fatalf("expected manhattan distance 7, got %d", d)
+	}
+	if d := a.Chebyshev(b); d != 4 {
+		t.Fatalf("expected chebyshev distance 4, got %d", d)
+	}
+	if v := a.Step(South, 3); v != (Vec{X: 1, Y: 5}) {
+		t.Fatalf("unexpected step result %s", v)
+	}
+	if v := FromIndex(a.Index(7), 7); v != a {
+		t.Fatalf("index round trip failed: %s", v)
+	}
+}
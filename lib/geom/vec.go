@@ -0,0 +1,68 @@
+// Package geom contains directions and integer vectors shared by the grid based puzzles.
+package geom
+
+import "fmt"
+
+// Vec is a position or offset on a grid.
+type Vec struct {
+	X, Y int
+}
+
+func (v Vec) String() string {
+	return fmt.Sprintf("(%d,%d)", v.X, v.Y)
+}
+
+func (v Vec) Add(o Vec) Vec {
+	return Vec{X: v.X + o.X, Y: v.Y + o.Y}
+}
+
+func (v Vec) Sub(o Vec) Vec {
+	return Vec{X: v.X - o.X, Y: v.Y - o.Y}
+}
+
+func (v Vec) Mul(k int) Vec {
+	return Vec{X: v.X * k, Y: v.Y * k}
+}
+
+func (v Vec) Neg() Vec {
+	return Vec{X: -v.X, Y: -v.Y}
+}
+
+// Step moves v n cells into direction d.
+func (v Vec) Step(d Dir, n int) Vec {
+	return v.Add(d.Vec().Mul(n))
+}
+
+// Manhattan returns the taxicab distance between v and o.
+func (v Vec) Manhattan(o Vec) int {
+	return abs(v.X-o.X) + abs(v.Y-o.Y)
+}
+
+// Chebyshev returns the chessboard distance between v and o.
+func (v Vec) Chebyshev(o Vec) int {
+	return max(abs(v.X-o.X), abs(v.Y-o.Y))
+}
+
+// In reports whether v lies within a grid of the given size.
+func (v Vec) In(cols, rows int) bool {
+	return v.X >= 0 && v.Y >= 0 && v.X < cols && v.Y < rows
+}
+
+// Index converts v to the index of a row major grid with cols columns,
+// as used by the flat field slices of the grids.
+// The result is only meaningful if 0 <= v.X < cols.
+func (v Vec) Index(cols int) int {
+	return v.Y*cols + v.X
+}
+
+// FromIndex is the inverse of Vec.Index.
+func FromIndex(idx, cols int) Vec {
+	return Vec{X: idx % cols, Y: idx / cols}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,3 @@
+module github.com/floj/aoc2024/lib
+
+go 1.23.4
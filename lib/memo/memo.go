@@ -0,0 +1,131 @@
+// Package memo provides a typed memoization cache for recursive counters.
+package memo
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Cache maps comparable keys to previously computed values.
+// A cache created with NewSync may be shared between goroutines,
+// a cache created with New must only be used by a single goroutine.
+type Cache[K comparable, V any] struct {
+	mu    sync.Locker
+	m     map[K]V
+	limit int
+	// insertion order of the keys, only tracked if the cache is bounded
+	order []K
+
+	hits, misses, evictions atomic.Uint64
+}
+
+// Stats holds the counters of a Cache.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+func (s Stats) String() string {
+	ratio := 0.0
+	if total := s.Hits + s.Misses; total > 0 {
+		ratio = float64(s.Hits) / float64(total) * 100
+	}
+	return fmt.Sprintf("size=%d hits=%d misses=%d evictions=%d hit-ratio=%.1f%%", s.Size, s.Hits, s.Misses, s.Evictions, ratio)
+}
+
+type noLock struct{}
+
+func (noLock) Lock()   {}
+func (noLock) Unlock() {}
+
+// New creates a cache for single goroutine use.
+// If limit is greater than 0, the oldest entries are evicted once the cache holds more than limit entries.
+func New[K comparable, V any](limit int) *Cache[K, V] {
+	return &Cache[K, V]{
+		mu:    noLock{},
+		m:     map[K]V{},
+		limit: limit,
+	}
+}
+
+// NewSync creates a cache that is safe for concurrent use.
+// The limit has the same meaning as for New.
+func NewSync[K comparable, V any](limit int) *Cache[K, V] {
+	c := New[K, V](limit)
+	c.mu = &sync.Mutex{}
+	return c
+}
+
+// Get returns the value stored for k and records a hit or miss.
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	c.mu.Lock()
+	v, ok := c.m[k]
+	c.mu.Unlock()
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return v, ok
+}
+
+// Set stores v for k and returns v, so it can be used in return statements.
+// If k is already present, the existing value is kept and returned.
+func (c *Cache[K, V]) Set(k K, v V) V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if o, ok := c.m[k]; ok {
+		return o
+	}
+	c.m[k] = v
+	if c.limit <= 0 {
+		return v
+	}
+	c.order = append(c.order, k)
+	for len(c.m) > c.limit {
+		delete(c.m, c.order[0])
+		c.order = c.order[1:]
+		c.evictions.Add(1)
+	}
+	return v
+}
+
+// Do returns the value stored for k or calls fn to compute and store it.
+// The cache is not locked while fn runs, so fn may call Do recursively.
+func (c *Cache[K, V]) Do(k K, fn func() V) V {
+	if v, ok := c.Get(k); ok {
+		return v
+	}
+	return c.Set(k, fn())
+}
+
+// Len returns the number of entries in the cache.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.m)
+}
+
+// Reset removes all entries and clears the statistics.
+func (c *Cache[K, V]) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.m)
+	c.order = nil
+	c.hits.Store(0)
+	c.misses.Store(0)
+	c.evictions.Store(0)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      c.Len(),
+	}
+}
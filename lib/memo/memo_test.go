@@ -0,0 +1,61 @@
+package memo
+
+import (
+	"sync"
+	"testing"
+)
+
+func fib(c *Cache[int, uint64], n int) uint64 {
+	if n < 2 {
+		return uint64(n)
+	}
+	return c.Do(n, func() uint64 {
+		return fib(c, n-1) + fib(c, n-2)
+	})
+}
+
+func TestDo(t *testing.T) {
+	c := New[int, uint64](0)
+	if v := fib(c, 90); v != 2880067194370816120 {
+		t.Fatalf("wrong result for fib(90): %d", v)
+	}
+	s := c.Stats()
+	if s.Size != 89 {
+		t.Fatalf("expected 89 entries, got %d", s.Size)
+	}
+	if s.Misses != 89 || s.Hits != 87 {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	c := New[string, int](2)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("expected oldest entry to be evicted")
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Fatalf("expected c=3, got %d (%v)", v, ok)
+	}
+	if s := c.Stats(); s.Size != 2 || s.Evictions != 1 {
+		t.Fatalf("unexpected stats: %s", s)
+	}
+}
+
+func TestSync(t *testing.T) {
+	c := NewSync[int, uint64](0)
+	wg := sync.WaitGroup{}
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fib(c, 80)
+		}()
+	}
+	wg.Wait()
+	if v, _ := c.Get(80); v != 23416728348467685 {
+		t.Fatalf("wrong result for fib(80): %d", v)
+	}
+}